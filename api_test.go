@@ -0,0 +1,76 @@
+package codeowner_test
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/kevin-robayna/codeowner"
+)
+
+// The assignments below fail to compile if the public API changes in a way
+// that breaks callers.
+var (
+	_ func(context.Context, string, ...codeowner.Option) ([]codeowner.Mapping, error) = codeowner.Scan
+	_ func(context.Context, string, ...codeowner.Option) ([]byte, error)              = codeowner.Generate
+	_ func(context.Context, fs.FS, ...codeowner.Option) ([]codeowner.Mapping, error)  = codeowner.ScanFS
+	_ func(context.Context, fs.FS, ...codeowner.Option) ([]byte, error)               = codeowner.GenerateFS
+	_ func([]codeowner.Mapping, codeowner.Format) ([]byte, error)                     = codeowner.Render
+
+	_ func(string) codeowner.Option                  = codeowner.WithPrefix
+	_ func(string) codeowner.Option                  = codeowner.WithDirOwnerFile
+	_ func(int) codeowner.Option                     = codeowner.WithHeaderLines
+	_ func(bool) codeowner.Option                    = codeowner.WithFollowSymlinks
+	_ func(func(string, bool) bool) codeowner.Option = codeowner.WithFilter
+	_ func(int) codeowner.Option                     = codeowner.WithConcurrency
+	_ func(codeowner.Format) codeowner.Option        = codeowner.WithFormat
+	_ func(...string) codeowner.Option               = codeowner.WithProtect
+	_ func(map[string][]string) codeowner.Option     = codeowner.WithAliases
+	_ func(...string) codeowner.Option               = codeowner.WithExtraPrefixes
+	_ func(...codeowner.Pattern) codeowner.Option    = codeowner.WithPatterns
+	_ func(bool) codeowner.Option                    = codeowner.WithLanguageAnnotations
+	_ func(...string) codeowner.Option               = codeowner.WithMetadata
+	_ func(string) codeowner.Option                  = codeowner.WithBackstageOwner
+	_ func() []string                                = codeowner.MetadataFiles
+	_                                                = codeowner.Mapping{Path: "/", Owners: []string{"@a"}, Comments: nil, Prefixes: nil}
+	_                                                = codeowner.Pattern{Regex: "", Owner: ""}
+	_                                                = codeowner.Options{
+		Prefix:              codeowner.DefaultPrefix,
+		ExtraPrefixes:       nil,
+		DirOwnerFile:        codeowner.DefaultDirOwnerFile,
+		HeaderLines:         0,
+		FollowSymlinks:      false,
+		Filter:              nil,
+		Concurrency:         0,
+		Format:              codeowner.FormatCodeOwners,
+		Protect:             nil,
+		Aliases:             nil,
+		Patterns:            nil,
+		LanguageAnnotations: false,
+		Metadata:            nil,
+		BackstageOwner:      "",
+	}
+)
+
+func TestAPIConstants(t *testing.T) {
+	t.Parallel()
+
+	// These values are part of the documented behavior and must not change.
+	checks := map[string]string{
+		"DefaultPrefix":       codeowner.DefaultPrefix,
+		"DefaultDirOwnerFile": codeowner.DefaultDirOwnerFile,
+		"FormatCodeOwners":    string(codeowner.FormatCodeOwners),
+		"FormatJSON":          string(codeowner.FormatJSON),
+	}
+	want := map[string]string{
+		"DefaultPrefix":       "CodeOwner:",
+		"DefaultDirOwnerFile": ".codeowner",
+		"FormatCodeOwners":    "codeowners",
+		"FormatJSON":          "json",
+	}
+	for name, got := range checks {
+		if got != want[name] {
+			t.Errorf("%s = %q, want %q", name, got, want[name])
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package codeowner scans source trees for CodeOwner annotations and
+// .codeowner files and renders the result as a GitHub CODEOWNERS file.
+//
+// It is the library form of the codeowner command:
+//
+//	out, err := codeowner.Generate(ctx, ".", codeowner.WithPrefix("Owner:"))
+package codeowner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+// DefaultPrefix is the annotation prefix searched for by default.
+const DefaultPrefix = scanning.DefaultPrefix
+
+// DefaultDirOwnerFile is the default filename for directory-level ownership.
+const DefaultDirOwnerFile = scanning.CodeOwnerFile
+
+// Mapping holds a CODEOWNERS path pattern and its owners. File paths are
+// root-anchored ("/src/main.go") and directory paths end with a slash
+// ("/src/").
+type Mapping struct {
+	Path   string   `json:"path"`
+	Owners []string `json:"owners"`
+	// Comments are the rationales given after "--" in the annotations.
+	Comments []string `json:"comments,omitempty"`
+	// Prefixes are the annotation prefixes the owners were found with, set
+	// only when scanning with extra prefixes.
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// Format selects the output produced by Generate.
+type Format string
+
+// Supported output formats.
+const (
+	// FormatCodeOwners renders a GitHub CODEOWNERS file.
+	FormatCodeOwners Format = "codeowners"
+	// FormatJSON renders the mappings as a JSON array.
+	FormatJSON Format = "json"
+)
+
+// Scan walks root and returns the ownership mappings found, in walk order.
+// It returns ctx.Err() if ctx is done before the scan completes.
+func Scan(ctx context.Context, root string, opts ...Option) ([]Mapping, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	found, err := scan(ctx, root, o)
+	if err != nil {
+		return nil, err
+	}
+	return fromInternal(found), nil
+}
+
+// ScanFS is like Scan but walks fsys, such as an embed.FS or a zip.Reader,
+// instead of a directory. Symlinks are only followed if fsys implements
+// fs.ReadLinkFS.
+func ScanFS(ctx context.Context, fsys fs.FS, opts ...Option) ([]Mapping, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	found, err := scanFS(ctx, fsys, o)
+	if err != nil {
+		return nil, err
+	}
+	return fromInternal(found), nil
+}
+
+// Generate scans root and renders the mappings in the configured format.
+func Generate(ctx context.Context, root string, opts ...Option) ([]byte, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	found, err := scan(ctx, root, o)
+	if err != nil {
+		return nil, err
+	}
+	return render(found, o.Format)
+}
+
+// GenerateFS is like Generate but walks fsys instead of a directory.
+func GenerateFS(ctx context.Context, fsys fs.FS, opts ...Option) ([]byte, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	found, err := scanFS(ctx, fsys, o)
+	if err != nil {
+		return nil, err
+	}
+	return render(found, o.Format)
+}
+
+// Render formats mappings, such as those returned by Scan, in format.
+func Render(mappings []Mapping, format Format) ([]byte, error) {
+	return render(toInternal(mappings), format)
+}
+
+// scan runs the internal scanner over the directory root.
+func scan(ctx context.Context, root string, o Options) ([]scanning.Mapping, error) {
+	found, err := scanning.ScanContext(ctx, root, o.internal())
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", root, err)
+	}
+	return withProtect(found, o)
+}
+
+// scanFS runs the internal scanner over fsys.
+func scanFS(ctx context.Context, fsys fs.FS, o Options) ([]scanning.Mapping, error) {
+	found, err := scanning.ScanFS(ctx, fsys, o.internal())
+	if err != nil {
+		return nil, fmt.Errorf("scanning: %w", err)
+	}
+	return withProtect(found, o)
+}
+
+// withProtect appends the protect rule configured in o, if any, with its
+// aliases expanded.
+func withProtect(found []scanning.Mapping, o Options) ([]scanning.Mapping, error) {
+	if len(o.Protect) > 0 {
+		pm, pErr := scanning.ParseProtect(strings.Join(o.Protect, " "))
+		if pErr != nil {
+			return nil, fmt.Errorf("protect: %w", pErr)
+		}
+		pm.Owners = scanning.Aliases(o.Aliases).Expand(pm.Owners)
+		found = append(found, pm)
+	}
+	return found, nil
+}
+
+// render formats internal mappings in format.
+func render(mappings []scanning.Mapping, format Format) ([]byte, error) {
+	switch format {
+	case FormatCodeOwners:
+		return []byte(formatter.CodeOwners(mappings)), nil
+	case FormatJSON:
+		out, err := json.MarshalIndent(fromInternal(mappings), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func fromInternal(mappings []scanning.Mapping) []Mapping {
+	out := make([]Mapping, len(mappings))
+	for i, m := range mappings {
+		out[i] = Mapping{Path: m.Path, Owners: m.Owners, Comments: m.Comments, Prefixes: m.Prefixes}
+	}
+	return out
+}
+
+func toInternal(mappings []Mapping) []scanning.Mapping {
+	out := make([]scanning.Mapping, len(mappings))
+	for i, m := range mappings {
+		out[i] = scanning.Mapping{Path: m.Path, Owners: m.Owners, Comments: m.Comments, Prefixes: m.Prefixes}
+	}
+	return out
+}
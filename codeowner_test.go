@@ -0,0 +1,192 @@
+package codeowner_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner"
+)
+
+func TestScan_MatchesSequential(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sequential, err := codeowner.Scan(ctx, "testdata", codeowner.WithConcurrency(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parallel, err := codeowner.Scan(ctx, "testdata", codeowner.WithConcurrency(8))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sequential) < 30 {
+		t.Errorf("expected at least 30 mappings, got %d", len(sequential))
+	}
+	if !slices.EqualFunc(sequential, parallel, func(a, b codeowner.Mapping) bool {
+		return a.Path == b.Path && slices.Equal(a.Owners, b.Owners)
+	}) {
+		t.Errorf("parallel scan differs from sequential scan:\nsequential: %v\nparallel:   %v", sequential, parallel)
+	}
+}
+
+func TestScan_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := codeowner.Scan(ctx, "testdata")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGenerate_Options(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("// Owner: @backend\npackage main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "OWNERS"), []byte("@root-team\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := codeowner.Generate(context.Background(), dir,
+		codeowner.WithPrefix("Owner:"),
+		codeowner.WithDirOwnerFile("OWNERS"),
+		codeowner.WithProtect("@admin"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "CODEOWNERS @admin\n" +
+		"\n" +
+		"/ @root-team\n" +
+		"/main.go @backend\n"
+	if string(out) != want {
+		t.Errorf("Generate:\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestGenerate_InvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		opt  codeowner.Option
+		want string
+	}{
+		{name: "negative header lines", opt: codeowner.WithHeaderLines(-1), want: "header lines"},
+		{name: "negative concurrency", opt: codeowner.WithConcurrency(-1), want: "concurrency"},
+		{name: "unknown format", opt: codeowner.WithFormat("yaml"), want: "unknown format"},
+		{name: "invalid protect owner", opt: codeowner.WithProtect("admin"), want: "protect"},
+		{name: "alias cycle", opt: codeowner.WithAliases(map[string][]string{"@a": {"@b"}, "@b": {"@a"}}), want: "alias cycle"},
+		{name: "pattern without owners group", opt: codeowner.WithPatterns(codeowner.Pattern{Regex: `team=(\w+)`}), want: "pattern 1: "},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := codeowner.Generate(context.Background(), "testdata/nested", tc.opt)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestGenerate_Aliases(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"pay/.codeowner": {Data: []byte("@payments\n")},
+		"pay/api.go":     {Data: []byte("// CodeOwner: @payments @reviewers\npackage pay\n")},
+	}
+
+	out, err := codeowner.GenerateFS(context.Background(), fsys,
+		codeowner.WithProtect("@admins"),
+		codeowner.WithAliases(map[string][]string{
+			"@payments":  {"@org/payments-backend", "@org/payments-sre"},
+			"@reviewers": {"@payments", "@org/security"},
+			"@admins":    {"@org/admins"},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "CODEOWNERS @org/admins\n" +
+		"\n" +
+		"/pay/ @org/payments-backend @org/payments-sre\n" +
+		"/pay/api.go @org/payments-backend @org/payments-sre @org/security\n"
+	if string(out) != want {
+		t.Errorf("GenerateFS:\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestGenerate_Patterns(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"api/handler.ts": {Data: []byte("/** @owner {team: backend} */\n")},
+		"main.go":        {Data: []byte("// CodeOwner: @go\npackage main\n")},
+	}
+	out, err := codeowner.GenerateFS(context.Background(), fsys,
+		codeowner.WithPatterns(codeowner.Pattern{Regex: `@owner \{team: (?P<owners>[\w-]+)\}`, Owner: "@org/{owner}"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/main.go @go\n\n/api/handler.ts @org/backend\n"; string(out) != want {
+		t.Errorf("GenerateFS:\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	mappings := []codeowner.Mapping{
+		{Path: "/src/main.go", Owners: []string{"@backend"}},
+		{Path: "/README.md", Owners: []string{"@docs"}},
+	}
+
+	out, err := codeowner.Render(mappings, codeowner.FormatCodeOwners)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "/README.md @docs\n\n/src/main.go @backend\n"
+	if string(out) != want {
+		t.Errorf("Render:\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestGenerateFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"api/.codeowner": {Data: []byte("@api-team\n")},
+		"api/handler.go": {Data: []byte("// CodeOwner: @handler-team\npackage api\n")},
+		"README.md":      {Data: []byte("<!-- CodeOwner: @docs -->\n")},
+	}
+
+	out, err := codeowner.GenerateFS(context.Background(), fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "/README.md @docs\n" +
+		"\n" +
+		"/api/ @api-team\n" +
+		"/api/handler.go @handler-team\n"
+	if string(out) != want {
+		t.Errorf("GenerateFS:\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
@@ -0,0 +1,63 @@
+package codeowner_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner"
+)
+
+func ExampleGenerate() {
+	out, err := codeowner.Generate(context.Background(), "testdata/dirowner")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(string(out))
+	// Output:
+	// / @dir-owner
+	// /app.go @app-team
+	//
+	// /sub/ @sub-team
+	// /sub/handler.go @handler-team
+	//
+	// /sub/deep/util.go @util-team
+}
+
+func ExampleScan() {
+	mappings, err := codeowner.Scan(context.Background(), "testdata/nested",
+		codeowner.WithFilter(func(path string, isDir bool) bool {
+			return !strings.HasPrefix(path, "deeply/.hidden")
+		}),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, m := range mappings {
+		fmt.Println(m.Path, m.Owners)
+	}
+	// Output:
+	// /deeply/nested/config.yaml [@infra-team]
+	// /deeply/service.py [@platform-team]
+	// /handler.go [@api-team]
+}
+
+func ExampleWithFormat() {
+	out, err := codeowner.Generate(context.Background(), "testdata/nested/deeply/nested",
+		codeowner.WithFormat(codeowner.FormatJSON),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(string(out))
+	// Output:
+	// [
+	//   {
+	//     "path": "/config.yaml",
+	//     "owners": [
+	//       "@infra-team"
+	//     ]
+	//   }
+	// ]
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/rewrite"
+	"github.com/spf13/cobra"
+)
+
+func newExportBackstageCmd() *cobra.Command {
+	var flags scanFlags
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "export-backstage [path]",
+		Short: "Write the owners of Backstage components into their catalog-info.yaml",
+		Long: "Scans the directory and sets the " + rewrite.BackstageAnnotation + " annotation of every Component in a\n" +
+			"catalog-info.yaml file to the owners the generated CODEOWNERS file gives that file.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			opts, err := flags.options(dir)
+			if err != nil {
+				return err
+			}
+			mappings, err := scanSource(cmd, dir, nil, opts, &flags)
+			if err != nil {
+				return fmt.Errorf("scanning directory: %w", err)
+			}
+			rules := matcher.ParseString(formatter.CodeOwners(mappings))
+
+			edits, err := rewrite.ExportBackstage(cmd.Context(), os.DirFS(dir), opts, rules.Owners)
+			if err != nil {
+				return fmt.Errorf("exporting to Backstage: %w", err)
+			}
+
+			if dryRun {
+				return rewrite.WriteDiff(cmd.OutOrStdout(), edits)
+			}
+			return applyEdits(cmd, dir, edits)
+		},
+	}
+
+	flags.registerCache(cmd)
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes as a unified diff instead of writing them")
+
+	return cmd
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kevin-robayna/codeowner/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	var flags scanFlags
+	var base, head, format string
+
+	cmd := &cobra.Command{
+		Use:   "diff [path]",
+		Short: "Show how ownership changes between two git revisions",
+		Long: "Scans the trees of two revisions from the local git repository and reports files whose\n" +
+			"effective owners changed, added, removed and changed rules, and the files each owner gained or lost.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			write := diff.WriteText
+			switch format {
+			case "text":
+			case "markdown":
+				write = diff.WriteMarkdown
+			default:
+				return fmt.Errorf("--format must be text or markdown, got %q", format)
+			}
+
+			baseSnap, err := loadSnapshot(cmd.Context(), dir, base, &flags)
+			if err != nil {
+				return fmt.Errorf("--base: %w", err)
+			}
+			headSnap, err := loadSnapshot(cmd.Context(), dir, head, &flags)
+			if err != nil {
+				return fmt.Errorf("--head: %w", err)
+			}
+
+			return write(cmd.OutOrStdout(), diff.Compare(baseSnap, headSnap))
+		},
+	}
+
+	flags.register(cmd)
+	cmd.Flags().StringVar(&base, "base", "", "base revision, e.g. the pull request's target branch")
+	cmd.Flags().StringVar(&head, "head", "HEAD", "head revision")
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text or markdown")
+	_ = cmd.MarkFlagRequired("base")
+
+	return cmd
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffCmd(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("// CodeOwner: @old-team\npackage main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, dir, "base")
+	gitCmd(t, dir, "tag", "base")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("// CodeOwner: @new-team\npackage main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, dir, "head")
+
+	testCases := []struct {
+		format string
+		want   string
+	}{
+		{format: "text", want: "M /main.go: @old-team -> @new-team"},
+		{format: "markdown", want: "| modified | `/main.go` | `@old-team` | `@new-team` |"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			cmd := NewRootCmd()
+			cmd.SetOut(&buf)
+			cmd.SetArgs([]string{"diff", "--base", "base", "--head", "HEAD", "--format", tc.format, dir})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(buf.String(), tc.want) {
+				t.Errorf("expected output to contain %q, got:\n%s", tc.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestDiffCmd_ConfigChange(t *testing.T) {
+	t.Parallel()
+
+	// Only the alias changes between the revisions, so each must be scanned
+	// with its own configuration.
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	writeRepoFiles(t, dir, map[string]string{
+		"main.go":         "// CodeOwner: @payments\npackage main\n",
+		".codeowner.yaml": "aliases:\n  \"@payments\": [\"@org/old\"]\n",
+	})
+	commitAll(t, dir, "base")
+	writeRepoFiles(t, dir, map[string]string{".codeowner.yaml": "aliases:\n  \"@payments\": [\"@org/new\"]\n"})
+	commitAll(t, dir, "head")
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"diff", "--base", "HEAD~1", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "M /main.go: @org/old -> @org/new"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	cmd = NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--rev", "HEAD~1", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/main.go @org/old\n"; buf.String() != want {
+		t.Errorf("expected the base revision's alias, got:\n%s", buf.String())
+	}
+}
+
+func TestDiffCmd_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, dir, "base")
+
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing base", args: []string{"diff", dir}},
+		{name: "unknown base", args: []string{"diff", "--base", "nope", dir}},
+		{name: "unknown format", args: []string{"diff", "--base", "HEAD", "--format", "html", dir}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := NewRootCmd()
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(tc.args)
+			if err := cmd.Execute(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/config"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"github.com/spf13/cobra"
+)
+
+// scanFlags holds the flags that control how a tree is scanned, shared by
+// every command that scans.
+type scanFlags struct {
+	prefix         []string
+	dirOwner       string
+	protect        string
+	headerLines    int
+	followSymlinks bool
+	noCache        bool
+	config         string
+	metadata       []string
+	language       bool
+}
+
+// register adds the scan flags to cmd, except --no-cache.
+func (f *scanFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.prefix, "prefix", []string{scanning.DefaultPrefix},
+		"annotation prefix to search for; repeat to also accept legacy prefixes, the first one is canonical")
+	cmd.Flags().StringVar(&f.dirOwner, "dirowner", scanning.CodeOwnerFile, "filename for directory-level ownership")
+	cmd.Flags().StringVar(&f.protect, "protect", "", "owners for the CODEOWNERS file itself (e.g. \"@admin @team\")")
+	cmd.Flags().IntVar(&f.headerLines, "header-lines", 0,
+		fmt.Sprintf("only scan each file's leading comment block, up to N lines (0 scans whole files, %d is a good start)", scanning.DefaultHeaderLines))
+	cmd.Flags().BoolVar(&f.followSymlinks, "follow-symlinks", false, "follow symbolic links that stay inside the scanned directory")
+	cmd.Flags().StringVar(&f.config, "config", "", "configuration file (default: "+config.DefaultFile+" in the scanned directory, if present)")
+	cmd.Flags().BoolVar(&f.language, "language-annotations", false,
+		"also read \"Owner:\" lines in Go package docs and Python module docstrings, and Python __owner__ variables")
+	cmd.Flags().StringSliceVar(&f.metadata, "metadata", nil,
+		"package manifests whose declared owners own their directory ("+strings.Join(scanning.MetadataFiles(), ", ")+")")
+}
+
+// registerCache adds the scan flags to cmd, with --no-cache for commands
+// that scan the working tree through the cache. Scans of git revisions or of
+// the index always read every file.
+func (f *scanFlags) registerCache(cmd *cobra.Command) {
+	f.register(cmd)
+	cmd.Flags().BoolVar(&f.noCache, "no-cache", false, "re-read every file instead of reusing results cached by earlier scans")
+}
+
+// loadConfig reads the --config file, or the default one in dir.
+func (f *scanFlags) loadConfig(dir string) (config.Config, error) {
+	cfg, err := config.Load(dir, f.config)
+	if err != nil {
+		return config.Config{}, fmt.Errorf("loading config: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadConfigFS reads the --config file, or the default one at the root of
+// fsys, the tree being scanned.
+func (f *scanFlags) loadConfigFS(fsys fs.FS) (config.Config, error) {
+	if f.config != "" {
+		return f.loadConfig("")
+	}
+	cfg, err := config.LoadFS(fsys)
+	if err != nil {
+		return config.Config{}, fmt.Errorf("loading config: %w", err)
+	}
+	return cfg, nil
+}
+
+// configFile returns the configuration file used when scanning dir or, with
+// rev set, the tree of that revision: the --config file, or the default one
+// if fsys has it, or "" if there is none.
+func (f *scanFlags) configFile(fsys fs.FS, dir, rev string) string {
+	if f.config != "" {
+		return f.config
+	}
+	if _, err := fs.Stat(fsys, config.DefaultFile); err != nil {
+		return ""
+	}
+	if rev != "" {
+		return rev + ":" + config.DefaultFile
+	}
+	return filepath.Join(dir, config.DefaultFile)
+}
+
+// options validates the flags and returns the matching scanner options,
+// including the owner aliases, annotation patterns and Backstage settings of
+// the configuration for dir.
+func (f *scanFlags) options(dir string) (scanning.Options, error) {
+	cfg, err := f.loadConfig(dir)
+	if err != nil {
+		return scanning.Options{}, err
+	}
+	return f.optionsWith(cfg)
+}
+
+// optionsFS is like options, reading the default configuration from fsys,
+// the tree being scanned, rather than from the working tree.
+func (f *scanFlags) optionsFS(fsys fs.FS) (scanning.Options, error) {
+	cfg, err := f.loadConfigFS(fsys)
+	if err != nil {
+		return scanning.Options{}, err
+	}
+	return f.optionsWith(cfg)
+}
+
+// optionsWith is like options, with the settings of an already loaded
+// configuration.
+func (f *scanFlags) optionsWith(cfg config.Config) (scanning.Options, error) {
+	if f.headerLines < 0 {
+		return scanning.Options{}, fmt.Errorf("--header-lines must not be negative, got %d", f.headerLines)
+	}
+	if slices.Contains(f.prefix, "") {
+		return scanning.Options{}, fmt.Errorf("--prefix must not be empty")
+	}
+	readers, err := scanning.BuiltinMetadataReaders(f.metadata...)
+	if err != nil {
+		return scanning.Options{}, fmt.Errorf("--metadata: %w", err)
+	}
+	for i, r := range readers {
+		if _, ok := r.(scanning.Backstage); ok {
+			readers[i] = cfg.Backstage
+		}
+	}
+	return scanning.Options{
+		Prefix:              f.prefix[0],
+		ExtraPrefixes:       f.prefix[1:],
+		DirOwnerFile:        f.dirOwner,
+		HeaderLines:         f.headerLines,
+		FollowSymlinks:      f.followSymlinks,
+		Aliases:             cfg.Aliases,
+		Patterns:            cfg.Patterns,
+		LanguageAnnotations: f.language,
+		MetadataReaders:     readers,
+	}, nil
+}
+
+// reportLegacyPrefixes prints how many of mappings were found with each of
+// the extra prefixes in opts, and how to migrate them to the canonical one.
+// With list set, the paths of those mappings follow each count.
+func reportLegacyPrefixes(cmd *cobra.Command, mappings []scanning.Mapping, opts scanning.Options, list bool) {
+	for _, p := range opts.ExtraPrefixes {
+		var paths []string
+		for _, m := range mappings {
+			if slices.Contains(m.Prefixes, p) {
+				paths = append(paths, m.Path)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		cmd.PrintErrf("%d file(s) use the prefix %q, migrate them with: codeowner migrate-prefix %s %s\n",
+			len(paths), p, shellQuote(p), shellQuote(opts.Prefix))
+		if list {
+			for _, path := range paths {
+				cmd.PrintErrf("  %s\n", path)
+			}
+		}
+	}
+}
+
+// withProtect appends the --protect mapping to mappings, if one was given,
+// expanding the aliases in opts.
+func (f *scanFlags) withProtect(mappings []scanning.Mapping, opts scanning.Options) ([]scanning.Mapping, error) {
+	if f.protect == "" {
+		return mappings, nil
+	}
+	pm, err := scanning.ParseProtect(f.protect)
+	if err != nil {
+		return nil, fmt.Errorf("--protect: %w", err)
+	}
+	pm.Owners = opts.Aliases.Expand(pm.Owners)
+	return append(mappings, pm), nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/git"
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"github.com/spf13/cobra"
+)
+
+// codeOwnersPaths lists the locations GitHub reads a CODEOWNERS file from,
+// in the order it looks for them.
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+func newHookCmd() *cobra.Command {
+	var flags scanFlags
+	var output outputFlags
+	var codeOwners string
+
+	cmd := &cobra.Command{
+		Use:   "hook [path]",
+		Short: "Check staged changes for missing ownership, for use as a pre-commit hook",
+		Long: "Scans the files staged in the git index and fails if a newly added file has no owner,\n" +
+			"or if the staged CODEOWNERS file differs from the one generated from the index.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			fsys, err := git.IndexFS(cmd.Context(), dir)
+			if err != nil {
+				return fmt.Errorf("reading index: %w", err)
+			}
+			defer fsys.Close()
+
+			opts, err := flags.optionsFS(fsys)
+			if err != nil {
+				return err
+			}
+
+			mappings, err := scanning.ScanFS(cmd.Context(), fsys, opts)
+			if err != nil {
+				return fmt.Errorf("scanning index: %w", err)
+			}
+			mappings, err = flags.withProtect(mappings, opts)
+			if err != nil {
+				return err
+			}
+			added, err := git.AddedFiles(cmd.Context(), dir)
+			if err != nil {
+				return fmt.Errorf("listing staged files: %w", err)
+			}
+
+			generated, err := output.format(cmd, fsys, mappings, opts, nil)
+			if err != nil {
+				return err
+			}
+			problems, err := checkStaged(fsys, codeOwners, generated, added, &flags)
+			if err != nil {
+				return err
+			}
+			for _, p := range problems {
+				cmd.PrintErrln(p)
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("%d ownership problem(s) in staged changes", len(problems))
+			}
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	output.register(cmd)
+	cmd.Flags().StringVar(&codeOwners, "codeowners", "",
+		"path of the CODEOWNERS file to keep up to date (default: the first staged of .github/CODEOWNERS, CODEOWNERS, docs/CODEOWNERS)")
+
+	return cmd
+}
+
+// checkStaged returns a description of every added file without an owner
+// under the generated rules, and of a staged CODEOWNERS file that differs
+// from generated, ignoring generated headers. When codeOwners is empty the
+// CODEOWNERS file is looked up in the usual locations, and the staleness
+// check is skipped if there is none.
+func checkStaged(fsys fs.FS, codeOwners, generated string, added []string, flags *scanFlags) ([]string, error) {
+	var problems []string
+
+	path := codeOwners
+	if path == "" {
+		path = findCodeOwners(fsys)
+	}
+	if path != "" {
+		staged, err := fs.ReadFile(fsys, path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			problems = append(problems, fmt.Sprintf("%s: not staged, generate it with: codeowner > %s", path, path))
+		case err != nil:
+			return nil, fmt.Errorf("reading staged %s: %w", path, err)
+		case formatter.StripHeader(string(staged)) != formatter.StripHeader(generated):
+			problems = append(problems, fmt.Sprintf("%s: out of date, regenerate it with: codeowner > %s", path, path))
+		}
+	}
+
+	rules := matcher.ParseString(generated)
+	for _, name := range added {
+		if name == path || len(rules.Owners(name)) > 0 {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("/%s: no owner, add a %q annotation or a %s file", name, flags.prefix[0], flags.dirOwner))
+	}
+	return problems, nil
+}
+
+// findCodeOwners returns the first CODEOWNERS location present in fsys, or
+// "" if there is none.
+func findCodeOwners(fsys fs.FS) string {
+	for _, p := range codeOwnersPaths {
+		if _, err := fs.Stat(fsys, p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeRepoFiles writes files into dir, creating parent directories.
+func writeRepoFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHookCmd(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		// staged is written and staged on top of the initial commit.
+		staged map[string]string
+		// unstaged is written to the working tree only.
+		unstaged map[string]string
+		wantErr  bool
+		want     []string
+	}{
+		{
+			name: "annotated new file",
+			staged: map[string]string{
+				"new.go":             "// CodeOwner: @backend\npackage main\n",
+				".github/CODEOWNERS": "/main.go @backend\n/new.go @backend\n\n/api/ @api-team\n",
+			},
+		},
+		{
+			name:   "new file covered by directory owner",
+			staged: map[string]string{"api/new.go": "package api\n"},
+		},
+		{
+			name:    "new file without owner",
+			staged:  map[string]string{"new.go": "package main\n"},
+			wantErr: true,
+			want:    []string{"/new.go: no owner"},
+		},
+		{
+			name:     "annotation only in working tree",
+			staged:   map[string]string{"new.go": "package main\n"},
+			unstaged: map[string]string{"new.go": "// CodeOwner: @backend\npackage main\n"},
+			wantErr:  true,
+			want:     []string{"/new.go: no owner"},
+		},
+		{
+			name: "config only in working tree",
+			staged: map[string]string{
+				"main.go":            "// CodeOwner: @payments\npackage main\n",
+				".github/CODEOWNERS": "/main.go @payments\n\n/api/ @api-team\n",
+			},
+			unstaged: map[string]string{".codeowner.yaml": "aliases:\n  \"@payments\": [\"@org/payments\"]\n"},
+		},
+		{
+			name:    "stale CODEOWNERS",
+			staged:  map[string]string{"main.go": "// CodeOwner: @new-team\npackage main\n"},
+			wantErr: true,
+			want:    []string{".github/CODEOWNERS: out of date"},
+		},
+		{
+			name: "updated CODEOWNERS",
+			staged: map[string]string{
+				"main.go":            "// CodeOwner: @new-team\npackage main\n",
+				".github/CODEOWNERS": "/main.go @new-team\n\n/api/ @api-team\n",
+			},
+		},
+		{
+			name: "CODEOWNERS with generated header",
+			staged: map[string]string{
+				".github/CODEOWNERS": "# Code generated by codeowner. DO NOT EDIT.\n# Version: v0.1.0\n\n/main.go @backend\n\n/api/ @api-team\n",
+			},
+		},
+		{
+			name: "hand-edited CODEOWNERS with generated header",
+			staged: map[string]string{
+				".github/CODEOWNERS": "# Code generated by codeowner. DO NOT EDIT.\n# Version: v0.1.0\n\n/main.go @backend @me\n\n/api/ @api-team\n",
+			},
+			wantErr: true,
+			want:    []string{".github/CODEOWNERS: out of date"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			gitCmd(t, dir, "init", "-q")
+			writeRepoFiles(t, dir, map[string]string{
+				"main.go":            "// CodeOwner: @backend\npackage main\n",
+				"api/.codeowner":     "@api-team\n",
+				".github/CODEOWNERS": "/main.go @backend\n\n/api/ @api-team\n",
+			})
+			commitAll(t, dir, "initial")
+			writeRepoFiles(t, dir, tc.staged)
+			gitCmd(t, dir, "add", "-A")
+			writeRepoFiles(t, dir, tc.unstaged)
+
+			var stderr bytes.Buffer
+			cmd := NewRootCmd()
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{"hook", dir})
+			err := cmd.Execute()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, wantErr %v\nstderr:\n%s", err, tc.wantErr, stderr.String())
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(stderr.String(), w) {
+					t.Errorf("expected stderr to contain %q, got:\n%s", w, stderr.String())
+				}
+			}
+		})
+	}
+}
+
+func TestHookCmd_CodeOwnersFlag(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	writeRepoFiles(t, dir, map[string]string{"main.go": "// CodeOwner: @backend\npackage main\n"})
+	gitCmd(t, dir, "add", "-A")
+
+	// Without a CODEOWNERS file in the index only new files are checked.
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"hook", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd = NewRootCmd()
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"hook", "--codeowners", "CODEOWNERS", dir})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for a missing CODEOWNERS file")
+	}
+	if !strings.Contains(stderr.String(), "CODEOWNERS: not staged") {
+		t.Errorf("expected stderr to mention the missing CODEOWNERS, got:\n%s", stderr.String())
+	}
+}
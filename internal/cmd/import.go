@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/rewrite"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"github.com/spf13/cobra"
+)
+
+func newImportCmd() *cobra.Command {
+	var prefix, dirOwner, codeOwners string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import [path]",
+		Short: "Convert an existing CODEOWNERS file into annotations",
+		Long: "Reads a hand-written CODEOWNERS file and creates directory owner files for directory rules and\n" +
+			"CodeOwner annotations for single-file rules, reporting the rules it could not translate.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			fsys := os.DirFS(dir)
+			name := codeOwners
+			if name == "" {
+				name = findCodeOwners(fsys)
+			}
+			rules, err := readCodeOwners(dir, name)
+			if err != nil {
+				return err
+			}
+
+			opts := scanning.Options{Prefix: prefix, DirOwnerFile: dirOwner}
+			res, err := rewrite.Import(cmd.Context(), fsys, rules, opts)
+			if err != nil {
+				return fmt.Errorf("importing %s: %w", name, err)
+			}
+			reportImport(cmd, name, res)
+
+			if dryRun {
+				return rewrite.WriteDiff(cmd.OutOrStdout(), res.Edits)
+			}
+			return applyEdits(cmd, dir, res.Edits)
+		},
+	}
+
+	cmd.Flags().StringVar(&prefix, "prefix", scanning.DefaultPrefix, "annotation prefix to write")
+	cmd.Flags().StringVar(&dirOwner, "dirowner", scanning.CodeOwnerFile, "filename for directory-level ownership")
+	cmd.Flags().StringVar(&codeOwners, "codeowners", "",
+		"path of the CODEOWNERS file to import (default: the first of .github/CODEOWNERS, CODEOWNERS, docs/CODEOWNERS)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes as a unified diff instead of writing them")
+
+	return cmd
+}
+
+// readCodeOwners parses the CODEOWNERS file name in dir.
+func readCodeOwners(dir, name string) (matcher.Ruleset, error) {
+	if name == "" {
+		return nil, errors.New("no CODEOWNERS file found, use --codeowners to name it")
+	}
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return matcher.Parse(f)
+}
+
+// reportImport prints the rules of the CODEOWNERS file name that res could
+// not translate, and the files whose owners would change.
+func reportImport(cmd *cobra.Command, name string, res rewrite.ImportResult) {
+	for _, s := range res.Skipped {
+		cmd.PrintErrf("%s:%d: %s: %s\n", name, s.Line, s.Pattern, s.Reason)
+	}
+	if len(res.Changed) == 0 {
+		return
+	}
+	cmd.PrintErrf("warning: %d file(s) would get different owners than in %s:\n", len(res.Changed), name)
+	for _, c := range res.Changed {
+		cmd.PrintErrf("  %s\n", c)
+	}
+}
+
+// applyEdits writes edits to the files below dir and prints the path of
+// each file created or updated.
+func applyEdits(cmd *cobra.Command, dir string, edits []rewrite.Edit) error {
+	if err := rewrite.Apply(dir, edits); err != nil {
+		return err
+	}
+	for _, e := range edits {
+		verb := "updated"
+		if e.Before == nil {
+			verb = "created"
+		}
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "%s /%s\n", verb, e.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
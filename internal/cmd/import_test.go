@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportCmd(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		".github/CODEOWNERS": "/api/ @backend\n/main.go @core\n*.md @docs\n",
+		"api/handler.go":     "package api\n",
+		"main.go":            "package main\n",
+	})
+
+	// A dry run prints a diff and leaves the files alone.
+	var stdout, stderr bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"import", "--dry-run", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"--- /dev/null\n+++ b/api/.codeowner\n", "+// CodeOwner: @core\n"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected diff to contain %q, got:\n%s", want, stdout.String())
+		}
+	}
+	if want := ".github/CODEOWNERS:3: *.md: wildcard patterns"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("expected stderr to contain %q, got:\n%s", want, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "api", ".codeowner")); err == nil {
+		t.Fatal("dry run created api/.codeowner")
+	}
+
+	stdout.Reset()
+	cmd = NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"import", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "created /api/.codeowner\nupdated /main.go\n"; stdout.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", stdout.String(), want)
+	}
+
+	// The imported annotations generate the same rules.
+	stdout.Reset()
+	cmd = NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--no-cache", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/main.go @core\n\n/api/ @backend\n"; stdout.String() != want {
+		t.Errorf("generated:\ngot:  %q\nwant: %q", stdout.String(), want)
+	}
+}
+
+func TestImportCmd_NoCodeOwners(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"import", t.TempDir()})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "no CODEOWNERS file found") {
+		t.Errorf("expected missing CODEOWNERS error, got %v", err)
+	}
+}
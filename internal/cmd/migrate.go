@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kevin-robayna/codeowner/internal/rewrite"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"github.com/spf13/cobra"
+)
+
+func newMigratePrefixCmd() *cobra.Command {
+	var dirOwner string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate-prefix old new [path]",
+		Short: "Rewrite annotations using a legacy prefix to use the canonical one",
+		Long: "Rewrites annotations in place, replacing the old prefix (e.g. \"Owner:\") with the new one\n" +
+			"(e.g. \"CodeOwner:\") and leaving owners, comment syntax and whitespace untouched.",
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, to := args[0], args[1]
+			dir := "."
+			if len(args) > 2 {
+				dir = args[2]
+			}
+			if from == "" || to == "" {
+				return errors.New("prefixes must not be empty")
+			}
+			if from == to {
+				return fmt.Errorf("old and new prefix are both %q", from)
+			}
+
+			opts := scanning.Options{Prefix: to, DirOwnerFile: dirOwner}
+			edits, err := rewrite.MigratePrefix(cmd.Context(), os.DirFS(dir), opts, from, to)
+			if err != nil {
+				return fmt.Errorf("scanning directory: %w", err)
+			}
+
+			if dryRun {
+				return rewrite.WriteDiff(cmd.OutOrStdout(), edits)
+			}
+			return applyEdits(cmd, dir, edits)
+		},
+	}
+
+	cmd.Flags().StringVar(&dirOwner, "dirowner", scanning.CodeOwnerFile, "filename for directory-level ownership, whose files are left alone")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes as a unified diff instead of writing them")
+
+	return cmd
+}
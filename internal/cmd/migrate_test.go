@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigratePrefixCmd(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"legacy.go": "// Owner: @backend\npackage main\n",
+		"new.go":    "// CodeOwner: @backend\npackage main\n",
+	})
+
+	// Scanning with both prefixes reports the legacy one.
+	var stdout, stderr bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--no-cache", "--prefix", "CodeOwner:", "--prefix", "Owner:", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/legacy.go @backend\n/new.go @backend\n"; stdout.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", stdout.String(), want)
+	}
+	if want := `1 file(s) use the prefix "Owner:", migrate them with: codeowner migrate-prefix Owner: CodeOwner:`; !strings.Contains(stderr.String(), want) {
+		t.Errorf("expected stderr to contain %q, got:\n%s", want, stderr.String())
+	}
+
+	// --list-legacy lists the files below the count.
+	stdout.Reset()
+	stderr.Reset()
+	cmd = NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--no-cache", "--list-legacy", "--prefix", "CodeOwner:", "--prefix", "Owner:", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "codeowner migrate-prefix Owner: CodeOwner:\n  /legacy.go\n"; !strings.HasSuffix(stderr.String(), want) {
+		t.Errorf("expected stderr to end with %q, got:\n%s", want, stderr.String())
+	}
+
+	stdout.Reset()
+	cmd = NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"migrate-prefix", "Owner:", "CodeOwner:", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "updated /legacy.go\n"; stdout.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", stdout.String(), want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "legacy.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "// CodeOwner: @backend\npackage main\n" {
+		t.Errorf("legacy.go = %q", data)
+	}
+}
+
+func TestMigratePrefixCmd_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "same prefix", args: []string{"Owner:", "Owner:"}, wantErr: `old and new prefix are both "Owner:"`},
+		{name: "empty prefix", args: []string{"", "CodeOwner:"}, wantErr: "must not be empty"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := NewRootCmd()
+			cmd.SetArgs(append([]string{"migrate-prefix"}, append(tc.args, t.TempDir())...))
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/appinfo"
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// outputFlags holds the flags that control how the CODEOWNERS file is
+// written, shared by every command that generates one.
+type outputFlags struct {
+	collapse      bool
+	strict        bool
+	groupBy       string
+	groupDepth    int
+	groupComments bool
+	align         bool
+	header        bool
+}
+
+// register adds the output flags to cmd.
+func (f *outputFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.collapse, "collapse", false,
+		"replace the rules inside a directory with one directory rule when every file in it has the same owners")
+	cmd.Flags().BoolVar(&f.strict, "strict", false,
+		"fail instead of warning when the CODEOWNERS file exceeds GitHub's size limit or uses unsupported patterns")
+	cmd.Flags().StringVar(&f.groupBy, "group-by", string(formatter.GroupByPath), "group rules by \"path\" or \"owner\"")
+	cmd.Flags().IntVar(&f.groupDepth, "group-depth", formatter.DefaultGroupDepth, "number of leading directories rules are grouped by with --group-by path")
+	cmd.Flags().BoolVar(&f.groupComments, "group-comments", false, "add a comment naming each group of rules")
+	cmd.Flags().BoolVar(&f.align, "align", false, "align the owners of the rules in each group in one column")
+}
+
+// registerHeader adds the --header flag to cmd, for commands that print the
+// CODEOWNERS file rather than only compare it.
+func (f *outputFlags) registerHeader(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.header, "header", false, "start with a do-not-edit comment naming the version, command line and config that generated the file")
+}
+
+// format returns the CODEOWNERS file for mappings, the result of scanning
+// fsys with opts, starting with header if it is not nil. fsys is only read
+// when collapsing. Anything GitHub would ignore in the result is printed as
+// a warning, or is an error with --strict.
+func (f *outputFlags) format(cmd *cobra.Command, fsys fs.FS, mappings []scanning.Mapping, opts scanning.Options, header *formatter.Header) (string, error) {
+	layout, err := f.layout()
+	if err != nil {
+		return "", err
+	}
+	layout.Header = header
+	if f.collapse {
+		files, err := scanning.ListFiles(cmd.Context(), fsys, opts)
+		if err != nil {
+			return "", fmt.Errorf("listing files: %w", err)
+		}
+		mappings = formatter.Collapse(mappings, files)
+	}
+	out := formatter.Format(mappings, layout)
+
+	problems := formatter.Check(out)
+	for _, p := range problems {
+		if f.strict {
+			cmd.PrintErrf("CODEOWNERS %s\n", p)
+		} else {
+			cmd.PrintErrf("warning: CODEOWNERS %s\n", p)
+		}
+	}
+	if f.strict && len(problems) > 0 {
+		return "", fmt.Errorf("%d problem(s) GitHub would reject in the CODEOWNERS file", len(problems))
+	}
+	return out, nil
+}
+
+// layout validates the flags and returns the matching formatter options.
+func (f *outputFlags) layout() (formatter.Options, error) {
+	groupBy := formatter.GroupBy(f.groupBy)
+	if groupBy != formatter.GroupByPath && groupBy != formatter.GroupByOwner {
+		return formatter.Options{}, fmt.Errorf("--group-by must be %q or %q, got %q", formatter.GroupByPath, formatter.GroupByOwner, f.groupBy)
+	}
+	if f.groupDepth < 0 {
+		return formatter.Options{}, fmt.Errorf("--group-depth must not be negative, got %d", f.groupDepth)
+	}
+	return formatter.Options{
+		GroupBy:       groupBy,
+		GroupDepth:    f.groupDepth,
+		GroupComments: f.groupComments,
+		Align:         f.align,
+	}, nil
+}
+
+// newHeader returns the header for a CODEOWNERS file generated by cmd with
+// args and the configuration file configFile, or nil without --header.
+func (f *outputFlags) newHeader(cmd *cobra.Command, args []string, configFile string) *formatter.Header {
+	if !f.header {
+		return nil
+	}
+	return &formatter.Header{
+		Version: appinfo.Version,
+		Command: commandLine(cmd, args),
+		Config:  configFile,
+	}
+}
+
+// commandLine reconstructs the command line that ran cmd with args, listing
+// the flags that were set explicitly, except those that do not affect the
+// output. Repeated and list flags are written once per value.
+func commandLine(cmd *cobra.Command, args []string) string {
+	words := []string{cmd.CommandPath()}
+	cmd.Flags().Visit(func(fl *pflag.Flag) {
+		switch v := fl.Value.(type) {
+		case pflag.SliceValue:
+			for _, e := range v.GetSlice() {
+				words = append(words, "--"+fl.Name+"="+shellQuote(e))
+			}
+		default:
+			if fl.Name == "no-cache" || fl.Name == "list-legacy" {
+				return
+			}
+			if fl.Value.Type() == "bool" && fl.Value.String() == "true" {
+				words = append(words, "--"+fl.Name)
+			} else {
+				words = append(words, "--"+fl.Name+"="+shellQuote(fl.Value.String()))
+			}
+		}
+	})
+	for _, a := range args {
+		words = append(words, shellQuote(a))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes s for a POSIX shell if it contains anything but
+// characters that are safe unquoted.
+func shellQuote(s string) string {
+	safe := s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == ""
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/kevin-robayna/codeowner/internal/git"
+	"github.com/kevin-robayna/codeowner/internal/policy"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"github.com/spf13/cobra"
+)
+
+func newPolicyCmd() *cobra.Command {
+	var flags scanFlags
+	var base, format string
+
+	cmd := &cobra.Command{
+		Use:   "policy [path]",
+		Short: "Check files against the ownership policy in the configuration",
+		Long: "Scans the directory and evaluates the policy rules of the configuration file against every\n" +
+			"file, reporting files that lack the ownership a rule requires.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if format != "text" && format != "json" {
+				return fmt.Errorf("--format must be text or json, got %q", format)
+			}
+
+			cfg, err := flags.loadConfig(dir)
+			if err != nil {
+				return err
+			}
+			if len(cfg.Policy) == 0 {
+				return errors.New("no policy rules configured")
+			}
+
+			opts, err := flags.optionsWith(cfg)
+			if err != nil {
+				return err
+			}
+			mappings, err := scanSource(cmd, dir, nil, opts, &flags)
+			if err != nil {
+				return fmt.Errorf("scanning directory: %w", err)
+			}
+			files, err := policyFiles(cmd.Context(), dir, base, opts)
+			if err != nil {
+				return err
+			}
+
+			violations := policy.Evaluate(cfg.Policy, mappings, files)
+			if err := writeReport(cmd.OutOrStdout(), format, violations); err != nil {
+				return err
+			}
+			if len(violations) > 0 {
+				return fmt.Errorf("%d policy violation(s)", len(violations))
+			}
+			return nil
+		},
+	}
+
+	flags.registerCache(cmd)
+	cmd.Flags().StringVar(&base, "base", "", "only check files changed in git diff <base>...HEAD")
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text or json")
+
+	return cmd
+}
+
+// policyFiles lists the files in dir the policy is checked against: all of
+// them, or with base set only those changed in git diff base...HEAD.
+func policyFiles(ctx context.Context, dir, base string, opts scanning.Options) ([]string, error) {
+	files, err := scanning.ListFiles(ctx, os.DirFS(dir), opts)
+	if err != nil {
+		return nil, fmt.Errorf("listing files: %w", err)
+	}
+	if base == "" {
+		return files, nil
+	}
+	changed, err := git.ChangedFiles(ctx, dir, base)
+	if err != nil {
+		return nil, fmt.Errorf("reading changed files: %w", err)
+	}
+	keep := make(map[string]bool, len(changed))
+	for _, f := range changed {
+		keep[f] = true
+	}
+	return slices.DeleteFunc(files, func(f string) bool { return !keep[f] }), nil
+}
+
+// writeReport writes violations to w in format, text or json.
+func writeReport(w io.Writer, format string, violations []policy.Violation) error {
+	if format != "json" {
+		return writeViolations(w, violations)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(violations)
+}
+
+// writeViolations writes one line per violation.
+func writeViolations(w io.Writer, violations []policy.Violation) error {
+	for _, v := range violations {
+		if _, err := fmt.Fprintf(w, "%s: %s (%s)\n", v.Path, v.Message, v.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// policyRepo writes a tree with one policy violation into a temporary
+// directory and returns its path.
+func policyRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		".codeowner.yaml": "policy:\n" +
+			"  - name: services are owned\n" +
+			"    paths: [/services/]\n" +
+			"    require: owner\n",
+		"services/api/main.go": "// CodeOwner: @api\npackage main\n",
+		"services/jobs/run.go": "package jobs\n",
+		"tools/gen.go":         "package tools\n",
+	})
+	return dir
+}
+
+func TestPolicyCmd(t *testing.T) {
+	t.Parallel()
+
+	dir := policyRepo(t)
+
+	var stdout bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"policy", "--no-cache", dir})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 policy violation") {
+		t.Fatalf("expected a policy violation error, got %v", err)
+	}
+
+	want := "/services/jobs/run.go: no owner (services are owned)\n"
+	if stdout.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", stdout.String(), want)
+	}
+}
+
+func TestPolicyCmd_JSON(t *testing.T) {
+	t.Parallel()
+
+	dir := policyRepo(t)
+
+	var stdout bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"policy", "--no-cache", "--format", "json", dir})
+	_ = cmd.Execute()
+
+	var got []struct {
+		Path string `json:"path"`
+		Rule string `json:"rule"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+	}
+	if len(got) != 1 || got[0].Path != "/services/jobs/run.go" || got[0].Rule != "services are owned" {
+		t.Errorf("unexpected violations: %+v", got)
+	}
+}
+
+func TestPolicyCmd_Base(t *testing.T) {
+	t.Parallel()
+
+	dir := policyRepo(t)
+	gitCmd(t, dir, "init", "-q")
+	commitAll(t, dir, "base")
+	gitCmd(t, dir, "tag", "base")
+	writeRepoFiles(t, dir, map[string]string{"services/api/new.go": "// CodeOwner: @api\npackage main\n"})
+	commitAll(t, dir, "head")
+
+	// The existing violation is outside the changeset.
+	cmd := NewRootCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"policy", "--no-cache", "--base", "base", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPolicyCmd_NoRules(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"policy", "--no-cache", t.TempDir()})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "no policy rules") {
+		t.Errorf("expected error about missing rules, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kevin-robayna/codeowner/internal/rewrite"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"github.com/spf13/cobra"
+)
+
+func newRenameOwnerCmd() *cobra.Command {
+	var prefix []string
+	var dirOwner string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rename-owner @old @new [path]",
+		Short: "Rename an owner handle in every annotation and directory owner file",
+		Long: "Rewrites CodeOwner annotations and directory owner files in place, replacing the old handle\n" +
+			"with the new one and leaving comment syntax and whitespace untouched.",
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, to := args[0], args[1]
+			dir := "."
+			if len(args) > 2 {
+				dir = args[2]
+			}
+			for _, h := range []string{from, to} {
+				if err := scanning.ValidateOwner(h); err != nil {
+					return err
+				}
+			}
+
+			opts := scanning.Options{Prefix: prefix[0], ExtraPrefixes: prefix[1:], DirOwnerFile: dirOwner}
+			edits, err := rewrite.RenameOwner(cmd.Context(), os.DirFS(dir), opts, from, to)
+			if err != nil {
+				return fmt.Errorf("scanning directory: %w", err)
+			}
+
+			if dryRun {
+				return rewrite.WriteDiff(cmd.OutOrStdout(), edits)
+			}
+			return applyEdits(cmd, dir, edits)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&prefix, "prefix", []string{scanning.DefaultPrefix}, "annotation prefix to search for; repeat to rename in several")
+	cmd.Flags().StringVar(&dirOwner, "dirowner", scanning.CodeOwnerFile, "filename for directory-level ownership")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes as a unified diff instead of writing them")
+
+	return cmd
+}
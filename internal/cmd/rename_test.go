@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenameOwnerCmd(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"main.go":        "// CodeOwner: @old @other\npackage main\n",
+		"api/.codeowner": "@old\n",
+	})
+
+	// A dry run prints a diff and leaves the files alone.
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"rename-owner", "--dry-run", "@old", "@new", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"--- a/main.go", "-// CodeOwner: @old @other", "+// CodeOwner: @new @other", "+@new"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected diff to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "@old") {
+		t.Fatal("dry run modified main.go")
+	}
+
+	buf.Reset()
+	cmd = NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"rename-owner", "@old", "@new", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "updated /api/.codeowner\nupdated /main.go\n"; buf.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", buf.String(), want)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "// CodeOwner: @new @other\npackage main\n" {
+		t.Errorf("main.go = %q", data)
+	}
+}
+
+func TestRenameOwnerCmd_InvalidHandle(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"rename-owner", "@old", "new", t.TempDir()})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "must start with @") {
+		t.Errorf("expected invalid handle error, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/git"
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/suggest"
+	"github.com/spf13/cobra"
+)
+
+func newReviewersCmd() *cobra.Command {
+	var flags scanFlags
+	var base, format string
+
+	cmd := &cobra.Command{
+		Use:   "reviewers [path]",
+		Short: "Suggest the fewest owners that cover a set of changed files",
+		Long: "Reads changed files, one per line, from stdin (e.g. git diff --name-only) or from\n" +
+			"git diff <base>...HEAD with --base, and prints the smallest set of owners that together\n" +
+			"own every changed file under the generated CODEOWNERS rules, with the files each owner covers.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if format != "text" && format != "json" {
+				return fmt.Errorf("--format must be text or json, got %q", format)
+			}
+
+			var files []string
+			var err error
+			if base != "" {
+				files, err = git.ChangedFiles(cmd.Context(), dir, base)
+			} else {
+				files, err = readLines(cmd.InOrStdin())
+			}
+			if err != nil {
+				return fmt.Errorf("reading changed files: %w", err)
+			}
+
+			opts, err := flags.options(dir)
+			if err != nil {
+				return err
+			}
+			mappings, err := scanSource(cmd, dir, nil, opts, &flags)
+			if err != nil {
+				return fmt.Errorf("scanning directory: %w", err)
+			}
+			mappings, err = flags.withProtect(mappings, opts)
+			if err != nil {
+				return err
+			}
+
+			res := suggest.Reviewers(matcher.ParseString(formatter.CodeOwners(mappings)), files)
+			if format == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(res)
+			}
+			return writeReviewers(cmd.OutOrStdout(), res)
+		},
+	}
+
+	flags.registerCache(cmd)
+	cmd.Flags().StringVar(&base, "base", "", "read changed files from git diff <base>...HEAD instead of stdin")
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text or json")
+
+	return cmd
+}
+
+// readLines returns the non-blank lines of r.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// writeReviewers prints each suggested owner followed by the files they
+// cover, then any files nobody owns.
+func writeReviewers(w io.Writer, res suggest.Result) error {
+	var b strings.Builder
+	for _, r := range res.Reviewers {
+		noun := "files"
+		if len(r.Files) == 1 {
+			noun = "file"
+		}
+		fmt.Fprintf(&b, "%s (%d %s)\n", r.Owner, len(r.Files), noun)
+		for _, f := range r.Files {
+			fmt.Fprintf(&b, "  %s\n", f)
+		}
+	}
+	if len(res.Unowned) > 0 {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("Unowned files:\n")
+		for _, f := range res.Unowned {
+			fmt.Fprintf(&b, "  %s\n", f)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
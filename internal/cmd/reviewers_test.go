@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/suggest"
+)
+
+func writeReviewerTree(t *testing.T, dir string) {
+	t.Helper()
+
+	writeRepoFiles(t, dir, map[string]string{
+		"api/.codeowner":  "@backend @platform\n",
+		"api/handler.go":  "package api\n",
+		"web/.codeowner":  "@frontend @platform\n",
+		"web/index.html":  "<html></html>\n",
+		"docs/readme.txt": "CodeOwner: @docs\n",
+		"unowned.txt":     "nobody\n",
+	})
+}
+
+func TestReviewersCmd_Stdin(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeReviewerTree(t, dir)
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetIn(strings.NewReader("api/handler.go\nweb/index.html\nunowned.txt\n"))
+	cmd.SetArgs([]string{"reviewers", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "@platform (2 files)\n" +
+		"  /api/handler.go\n" +
+		"  /web/index.html\n" +
+		"\n" +
+		"Unowned files:\n" +
+		"  /unowned.txt\n"
+	if buf.String() != want {
+		t.Errorf("reviewers output:\ngot:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestReviewersCmd_Base(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	writeReviewerTree(t, dir)
+	commitAll(t, dir, "base")
+	gitCmd(t, dir, "tag", "base")
+	if err := os.WriteFile(filepath.Join(dir, "docs", "readme.txt"), []byte("CodeOwner: @docs\nmore\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, dir, "change docs")
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"reviewers", "--base", "base", "--format", "json", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var res suggest.Result
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, buf.String())
+	}
+	if len(res.Reviewers) != 1 || res.Reviewers[0].Owner != "@docs" {
+		t.Errorf("expected @docs as the only reviewer, got %+v", res)
+	}
+}
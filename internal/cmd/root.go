@@ -2,16 +2,16 @@ package cmd
 
 import (
 	"fmt"
+	"io/fs"
 
-	"github.com/kevin-robayna/codeowner/internal/formatter"
-	"github.com/kevin-robayna/codeowner/internal/scanning"
 	"github.com/spf13/cobra"
 )
 
 func NewRootCmd() *cobra.Command {
-	var prefix string
-	var dirOwner string
-	var protect string
+	var flags scanFlags
+	var output outputFlags
+	var rev string
+	var listLegacy bool
 
 	root := &cobra.Command{
 		Use:          "codeowner [path]",
@@ -25,17 +25,30 @@ func NewRootCmd() *cobra.Command {
 				dir = args[0]
 			}
 
-			mappings, err := scanning.ParseDir(dir, prefix, dirOwner)
+			fsys, closeFS, err := sourceFS(cmd.Context(), dir, rev)
+			if err != nil {
+				return err
+			}
+			defer closeFS()
+			opts, err := flags.optionsFS(fsys)
+			if err != nil {
+				return err
+			}
+
+			var tree fs.FS
+			if rev != "" {
+				tree = fsys
+			}
+			mappings, err := scanSource(cmd, dir, tree, opts, &flags)
 			if err != nil {
 				return fmt.Errorf("scanning directory: %w", err)
 			}
 
-			if protect != "" {
-				pm, pErr := scanning.ParseProtect(protect)
-				if pErr != nil {
-					return fmt.Errorf("--protect: %w", pErr)
-				}
-				mappings = append(mappings, pm)
+			reportLegacyPrefixes(cmd, mappings, opts, listLegacy)
+
+			mappings, err = flags.withProtect(mappings, opts)
+			if err != nil {
+				return err
 			}
 
 			if len(mappings) == 0 {
@@ -43,15 +56,33 @@ func NewRootCmd() *cobra.Command {
 				return nil
 			}
 
-			cmd.Print(formatter.CodeOwners(mappings))
-			return nil
+			header := output.newHeader(cmd, args, flags.configFile(fsys, dir, rev))
+			out, err := output.format(cmd, fsys, mappings, opts, header)
+			if err != nil {
+				return err
+			}
+
+			// cmd.Print writes to stderr unless an output is set, and the
+			// CODEOWNERS file must go to stdout to be redirected into place.
+			_, err = fmt.Fprint(cmd.OutOrStdout(), out)
+			return err
 		},
 	}
 
-	root.Flags().StringVar(&prefix, "prefix", scanning.DefaultPrefix, "annotation prefix to search for")
-	root.Flags().StringVar(&dirOwner, "dirowner", scanning.CodeOwnerFile, "filename for directory-level ownership")
-	root.Flags().StringVar(&protect, "protect", "", "owners for the CODEOWNERS file itself (e.g. \"@admin @team\")")
+	flags.registerCache(root)
+	output.register(root)
+	output.registerHeader(root)
+	root.Flags().BoolVar(&listLegacy, "list-legacy", false, "list the files found with each extra --prefix below its count")
+	root.Flags().StringVar(&rev, "rev", "", "scan the tree of a git revision (e.g. a tag or commit) instead of the working tree")
 	root.AddCommand(newVersionCmd())
+	root.AddCommand(newDiffCmd())
+	root.AddCommand(newReviewersCmd())
+	root.AddCommand(newHookCmd())
+	root.AddCommand(newPolicyCmd())
+	root.AddCommand(newRenameOwnerCmd())
+	root.AddCommand(newMigratePrefixCmd())
+	root.AddCommand(newImportCmd())
+	root.AddCommand(newExportBackstageCmd())
 
 	return root
 }
@@ -2,12 +2,39 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/kevin-robayna/codeowner/internal/appinfo"
+	"github.com/kevin-robayna/codeowner/internal/formatter"
 )
 
+// TestMain points the user cache directory at a temporary one, so that
+// commands scanning with the default cache leave nothing behind.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "codeowner-cache-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// os.UserCacheDir reads XDG_CACHE_HOME or HOME on Unix, HOME on macOS
+	// and LocalAppData on Windows.
+	for _, env := range []string{"XDG_CACHE_HOME", "HOME", "LocalAppData"} {
+		if err := os.Setenv(env, dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestExecute(t *testing.T) {
 	t.Parallel()
 
@@ -150,3 +177,451 @@ func TestRootCmd_NoProtect(t *testing.T) {
 		t.Errorf("expected output to contain /main.go mapping, got:\n%s", got)
 	}
 }
+
+func TestRootCmd_FollowSymlinks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("// CodeOwner: @backend\npackage main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("main.go", filepath.Join(dir, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--follow-symlinks", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "/link.go @backend") {
+		t.Errorf("expected output to contain /link.go mapping, got:\n%s", buf.String())
+	}
+}
+
+func TestRootCmd_NoCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(args ...string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		cmd := NewRootCmd()
+		cmd.SetOut(&buf)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append(args, dir))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return buf.String()
+	}
+
+	write("// CodeOwner: @aaa\n")
+	if got := run(); !strings.Contains(got, "/main.go @aaa") {
+		t.Fatalf("first run: got:\n%s", got)
+	}
+
+	// Same size and mtime: the cached result is reused unless disabled.
+	write("// CodeOwner: @bbb\n")
+	if got := run(); !strings.Contains(got, "/main.go @aaa") {
+		t.Errorf("cached run: got:\n%s", got)
+	}
+	if got := run("--no-cache"); !strings.Contains(got, "/main.go @bbb") {
+		t.Errorf("--no-cache run: got:\n%s", got)
+	}
+}
+
+func TestRootCmd_Aliases(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		".codeowner.yaml": "aliases:\n  \"@payments\": [\"@org/payments-backend\", \"@org/payments-sre\"]\n",
+		"pay/api.go":      "// CodeOwner: @payments\npackage pay\n",
+	})
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--no-cache", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/pay/api.go @org/payments-backend @org/payments-sre\n"; buf.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", buf.String(), want)
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"--no-cache", "--config", filepath.Join(dir, "missing.yaml"), dir})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "loading config") {
+		t.Errorf("expected config error, got %v", err)
+	}
+}
+
+// gitCmd runs git in dir and fails the test on error.
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// commitAll stages and commits every change in the repository dir.
+func commitAll(t *testing.T, dir, msg string) {
+	t.Helper()
+
+	gitCmd(t, dir, "add", "-A")
+	gitCmd(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", msg)
+}
+
+func TestRootCmd_Rev(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("// CodeOwner: @v1-team\npackage main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, dir, "v1")
+	gitCmd(t, dir, "tag", "v1.0.0")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("// CodeOwner: @v2-team\npackage main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--rev", "v1.0.0", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := buf.String(); got != "/main.go @v1-team\n" {
+		t.Errorf("expected ownership as of v1.0.0, got:\n%s", got)
+	}
+}
+
+func TestRootCmd_RevUnknown(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"--rev", "v9.9.9", dir})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for unknown revision")
+	}
+}
+
+func TestRootCmd_Collapse(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"README.md":  "# CodeOwner: @docs\n",
+		"api/a.go":   "// CodeOwner: @backend\npackage api\n",
+		"api/b.go":   "// CodeOwner: @backend\npackage api\n",
+		"web/app.ts": "// CodeOwner: @frontend\n",
+		"web/x.ts":   "export {}\n",
+	})
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--no-cache", "--collapse", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "/README.md @docs\n\n/api/ @backend\n\n/web/app.ts @frontend\n"
+	if buf.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", buf.String(), want)
+	}
+}
+
+func TestRootCmd_Strict(t *testing.T) {
+	t.Parallel()
+
+	// Files just under the 1 MB scan limit, each listing distinct owners,
+	// add up to a CODEOWNERS file over GitHub's 3 MB limit.
+	dir := t.TempDir()
+	files := make(map[string]string)
+	for i := range 4 {
+		var b strings.Builder
+		for j := range 4_500 {
+			b.WriteString("// CodeOwner:")
+			for k := range 20 {
+				fmt.Fprintf(&b, " @t%d_%d", i, j*20+k)
+			}
+			b.WriteByte('\n')
+		}
+		files[fmt.Sprintf("f%d.go", i)] = b.String()
+	}
+	writeRepoFiles(t, dir, files)
+
+	var stdout, stderr bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--no-cache", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "warning: CODEOWNERS file is") {
+		t.Errorf("expected a size warning, got stderr:\n%s", stderr.String())
+	}
+	if stdout.Len() <= formatter.MaxSize {
+		t.Errorf("expected output over %d bytes despite the warning, got %d", formatter.MaxSize, stdout.Len())
+	}
+
+	stdout.Reset()
+	cmd = NewRootCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--no-cache", "--strict", dir})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error with --strict")
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("expected no output with --strict, got %d bytes", stdout.Len())
+	}
+}
+
+func TestRootCmd_Layout(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"README.md":       "# CodeOwner: @docs\n",
+		"src/api/a.go":    "// CodeOwner: @backend\npackage api\n",
+		"src/cmd/main.go": "// CodeOwner: @backend\npackage main\n",
+	})
+
+	testCases := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "group by owner with comments and alignment",
+			args: []string{"--group-by", "owner", "--group-comments", "--align"},
+			want: "# @backend\n/src/api/a.go    @backend\n/src/cmd/main.go @backend\n\n# @docs\n/README.md @docs\n",
+		},
+		{
+			name: "group depth",
+			args: []string{"--group-depth", "1"},
+			want: "/README.md @docs\n\n/src/api/a.go @backend\n/src/cmd/main.go @backend\n",
+		},
+		{
+			name:    "invalid group by",
+			args:    []string{"--group-by", "team"},
+			wantErr: `--group-by must be "path" or "owner", got "team"`,
+		},
+		{
+			name:    "negative group depth",
+			args:    []string{"--group-depth", "-1"},
+			wantErr: "--group-depth must not be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			cmd := NewRootCmd()
+			cmd.SetOut(&buf)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append(append([]string{"--no-cache"}, tc.args...), dir))
+			err := cmd.Execute()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("output:\ngot:  %q\nwant: %q", buf.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestRootCmd_Header(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		".codeowner.yaml": "aliases:\n  \"@be\": [\"@backend\"]\n",
+		"main.go":         "// CodeOwner: @be\npackage main\n",
+	})
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--no-cache", "--header", "--protect", "@admin @ops",
+		"--prefix", "CodeOwner:", "--prefix", "Owner:", "--metadata", "package.json,go.mod", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Repeated and list flags are written once per value, and --no-cache,
+	// which does not change the output, is left out.
+	want := "# Code generated by codeowner. DO NOT EDIT.\n" +
+		"# Change the CodeOwner annotations instead and regenerate it with:\n" +
+		"#   codeowner --header --metadata=package.json --metadata=go.mod --prefix=CodeOwner: --prefix=Owner: --protect='@admin @ops' " + dir + "\n" +
+		"# Version: " + appinfo.Version + "\n" +
+		"# Config: " + filepath.Join(dir, ".codeowner.yaml") + "\n" +
+		"\n" +
+		"CODEOWNERS @admin @ops\n\n/main.go @backend\n"
+	if buf.String() != want {
+		t.Errorf("output:\ngot:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestRootCmd_Patterns(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		".codeowner.yaml": "patterns:\n  - regex: '@owner \\{team: (?P<owners>[\\w-]+)\\}'\n    owner: \"@org/{owner}\"\n",
+		"api/handler.ts":  "/** @owner {team: backend} */\n",
+		"main.go":         "// CodeOwner: @go\npackage main\n",
+	})
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--no-cache", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/main.go @go\n\n/api/handler.ts @org/backend\n"; buf.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", buf.String(), want)
+	}
+
+	// An invalid pattern is reported before scanning.
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(bad, []byte("patterns:\n  - regex: 'team=(\\w+)'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = NewRootCmd()
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--no-cache", "--config", bad, dir})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "loading config: "+bad+": pattern 1: ") {
+		t.Errorf("expected a pattern error naming the config file, got %v", err)
+	}
+}
+
+func TestRootCmd_Metadata(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"web/package.json": `{"name": "web", "codeowners": ["@frontend"]}`,
+		"core/Cargo.toml":  "[package]\nname = \"core\"\n\n[package.metadata.codeowner]\nowners = [\"@rust\"]\n",
+		"main.go":          "// CodeOwner: @go\npackage main\n",
+	})
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--no-cache", "--metadata", "package.json,Cargo.toml", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/main.go @go\n\n/core/ @rust\n\n/web/ @frontend\n"; buf.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", buf.String(), want)
+	}
+
+	// A broken manifest is skipped with a warning.
+	writeRepoFiles(t, dir, map[string]string{"node_modules/x/package.json": `{"name": `})
+	var stderr bytes.Buffer
+	buf.Reset()
+	cmd = NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--no-cache", "--metadata", "package.json,Cargo.toml", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/main.go @go\n\n/core/ @rust\n\n/web/ @frontend\n"; buf.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", buf.String(), want)
+	}
+	if !strings.HasPrefix(stderr.String(), "warning: skipping node_modules/x/package.json: ") {
+		t.Errorf("stderr: got %q, want a warning for the broken manifest", stderr.String())
+	}
+
+	cmd = NewRootCmd()
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--no-cache", "--metadata", "setup.py", dir})
+	if err := cmd.Execute(); err == nil || !strings.HasPrefix(err.Error(), "--metadata: ") {
+		t.Errorf("expected a --metadata error, got %v", err)
+	}
+}
+
+func TestExportBackstageCmd(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"web/.codeowner":        "@web\n",
+		"web/catalog-info.yaml": "kind: Component\nmetadata:\n  name: web\n",
+	})
+
+	var buf bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"export-backstage", "--no-cache", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "updated /web/catalog-info.yaml\n"; buf.String() != want {
+		t.Errorf("output: got %q, want %q", buf.String(), want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "web", "catalog-info.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "kind: Component\nmetadata:\n  annotations:\n    codeowner/owners: \"@web\"\n  name: web\n"; string(data) != want {
+		t.Errorf("catalog-info.yaml: got %q, want %q", data, want)
+	}
+
+	// Reading the catalog back with a group template gives its directory
+	// the owner of the component.
+	writeRepoFiles(t, dir, map[string]string{
+		".codeowner.yaml":       "backstage:\n  owner: \"@org/{owner}\"\n",
+		"api/catalog-info.yaml": "kind: Component\nspec:\n  owner: group:default/api-team\n",
+	})
+	buf.Reset()
+	cmd = NewRootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--no-cache", "--metadata", "catalog-info.yaml", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/api/ @org/api-team\n\n/web/ @web\n"; buf.String() != want {
+		t.Errorf("output:\ngot:  %q\nwant: %q", buf.String(), want)
+	}
+}
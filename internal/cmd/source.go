@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/kevin-robayna/codeowner/internal/diff"
+	"github.com/kevin-robayna/codeowner/internal/git"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"github.com/spf13/cobra"
+)
+
+// scanSource scans the directory dir or, when tree is set, that tree of a
+// git revision in the repository containing dir, as opened by sourceFS.
+// Working tree scans go through the on-disk cache unless flags disable it.
+// Problems that do not stop the scan are printed as warnings.
+func scanSource(cmd *cobra.Command, dir string, tree fs.FS, opts scanning.Options, flags *scanFlags) ([]scanning.Mapping, error) {
+	opts.Warn = func(err error) { cmd.PrintErrf("warning: %v\n", err) }
+	if tree == nil {
+		return scanWorkTree(cmd, dir, opts, flags)
+	}
+	return scanning.ScanFS(cmd.Context(), tree, opts)
+}
+
+// sourceFS returns the file system read for dir or, when rev is set, the
+// tree of that revision, and a function releasing it.
+func sourceFS(ctx context.Context, dir, rev string) (fs.FS, func(), error) {
+	if rev == "" {
+		return os.DirFS(dir), func() {}, nil
+	}
+	fsys, err := git.TreeFS(ctx, dir, rev)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fsys, func() { _ = fsys.Close() }, nil
+}
+
+// scanWorkTree scans the directory dir, reusing the results cached by earlier
+// scans for files that have not changed. The cache is only an optimisation,
+// so failing to locate or save it is reported as a warning.
+func scanWorkTree(cmd *cobra.Command, dir string, opts scanning.Options, flags *scanFlags) ([]scanning.Mapping, error) {
+	if flags.noCache {
+		return scanning.ScanContext(cmd.Context(), dir, opts)
+	}
+
+	cachePath, err := scanning.DefaultCachePath(dir)
+	if err != nil {
+		cmd.PrintErrf("warning: cache disabled: %v\n", err)
+		return scanning.ScanContext(cmd.Context(), dir, opts)
+	}
+	opts.Cache = scanning.OpenCache(cachePath, opts)
+
+	mappings, err := scanning.ScanContext(cmd.Context(), dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Cache.Save(); err != nil {
+		cmd.PrintErrf("warning: saving cache: %v\n", err)
+	}
+	return mappings, nil
+}
+
+// loadSnapshot scans the tree of rev in the git repository containing dir
+// and lists its files, with the configuration found in that tree.
+func loadSnapshot(ctx context.Context, dir, rev string, flags *scanFlags) (diff.Snapshot, error) {
+	fsys, err := git.TreeFS(ctx, dir, rev)
+	if err != nil {
+		return diff.Snapshot{}, err
+	}
+	defer fsys.Close()
+
+	opts, err := flags.optionsFS(fsys)
+	if err != nil {
+		return diff.Snapshot{}, err
+	}
+	mappings, err := scanning.ScanFS(ctx, fsys, opts)
+	if err != nil {
+		return diff.Snapshot{}, fmt.Errorf("scanning %s: %w", rev, err)
+	}
+	mappings, err = flags.withProtect(mappings, opts)
+	if err != nil {
+		return diff.Snapshot{}, err
+	}
+	files, err := scanning.ListFiles(ctx, fsys, opts)
+	if err != nil {
+		return diff.Snapshot{}, fmt.Errorf("listing %s: %w", rev, err)
+	}
+	return diff.Snapshot{Mappings: mappings, Files: files}, nil
+}
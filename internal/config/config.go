@@ -0,0 +1,110 @@
+// Package config loads the optional YAML configuration file that holds
+// settings too rich for command-line flags, such as owner aliases, annotation
+// patterns and ownership policies.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/kevin-robayna/codeowner/internal/policy"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultFile is the configuration file looked up in the scanned directory.
+const DefaultFile = ".codeowner.yaml"
+
+// Config is the contents of a configuration file.
+type Config struct {
+	// Aliases maps owner handles used in annotations to the handles written
+	// to CODEOWNERS, e.g. @payments to @org/payments-backend.
+	Aliases scanning.Aliases `yaml:"aliases"`
+	// Patterns are regular expressions matching annotations in addition to
+	// the annotation prefix.
+	Patterns []scanning.Pattern `yaml:"patterns"`
+	// Policy lists the rules checked by the policy command.
+	Policy []policy.Rule `yaml:"policy"`
+	// Backstage configures how the owners of Backstage catalog entities map
+	// to GitHub handles.
+	Backstage scanning.Backstage `yaml:"backstage"`
+}
+
+// Parse decodes and validates a configuration. Unknown fields are errors, so
+// that typos do not silently disable a setting.
+func Parse(data []byte) (Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, err
+	}
+	if err := c.validate(); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+func (c *Config) validate() error {
+	if err := c.Aliases.Validate(); err != nil {
+		return fmt.Errorf("aliases: %w", err)
+	}
+	for i := range c.Patterns {
+		if err := c.Patterns[i].Compile(); err != nil {
+			return fmt.Errorf("pattern %d: %w", i+1, err)
+		}
+	}
+	if err := c.Backstage.Validate(); err != nil {
+		return fmt.Errorf("backstage: %w", err)
+	}
+	for i, r := range c.Policy {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("policy rule %d (%s): %w", i+1, r, err)
+		}
+	}
+	return nil
+}
+
+// Load reads the configuration file at path. If path is empty, DefaultFile
+// in dir is read instead, and a missing file yields an empty Config.
+func Load(dir, path string) (Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(dir, DefaultFile)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return Config{}, nil
+		}
+		return Config{}, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// LoadFS reads DefaultFile from the root of fsys, such as the tree of a git
+// revision. A missing file yields an empty Config.
+func LoadFS(fsys fs.FS) (Config, error) {
+	data, err := fs.ReadFile(fsys, DefaultFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Config{}, nil
+		}
+		return Config{}, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", DefaultFile, err)
+	}
+	return c, nil
+}
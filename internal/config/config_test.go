@@ -0,0 +1,147 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/config"
+	"github.com/kevin-robayna/codeowner/internal/policy"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	c, err := config.Parse([]byte(`
+aliases:
+  "@payments": ["@org/payments-backend", "@org/payments-sre"]
+patterns:
+  - regex: '@owner \{team: (?P<owners>[\w-]+)\}'
+    owner: "@org/{owner}"
+policy:
+  - name: services are owned
+    paths: ["/services/"]
+    require: owner
+  - paths:
+      - "*.proto"
+    require: annotation
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := c.Aliases["@payments"]; len(got) != 2 || got[0] != "@org/payments-backend" {
+		t.Errorf("aliases = %v", c.Aliases)
+	}
+	if len(c.Patterns) != 1 || c.Patterns[0].Owner != "@org/{owner}" {
+		t.Errorf("patterns = %+v", c.Patterns)
+	}
+	if len(c.Policy) != 2 {
+		t.Fatalf("expected 2 policy rules, got %d", len(c.Policy))
+	}
+	if got := c.Policy[0]; got.Name != "services are owned" || got.Require != policy.RequireOwner || got.Paths[0] != "/services/" {
+		t.Errorf("rule 1 = %+v", got)
+	}
+	if got := c.Policy[1]; got.Require != policy.RequireAnnotation || got.Paths[0] != "*.proto" {
+		t.Errorf("rule 2 = %+v", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "unknown field", data: "polcy: []\n", want: "field polcy not found"},
+		{name: "invalid yaml", data: "policy: [\n", want: "yaml"},
+		{name: "alias cycle", data: "aliases:\n  \"@a\": [\"@a\"]\n", want: "aliases: alias cycle: @a -> @a"},
+		{name: "pattern without owners group", data: "patterns:\n  - regex: 'Owner: (\\S+)'\n", want: "pattern 1: regex \"Owner: (\\\\S+)\" has no (?P<owners>...) group"},
+		{name: "invalid pattern regex", data: "patterns:\n  - regex: '(?P<owners>['\n", want: "pattern 1: invalid regex: error parsing regexp: missing closing ]"},
+		{name: "backstage owner without placeholder", data: "backstage:\n  owner: \"@org/team\"\n", want: "backstage: owner \"@org/team\" does not contain {owner}"},
+		{name: "invalid rule", data: "policy:\n  - paths: [/a/]\n    require: team\n", want: `policy rule 1 (/a/): unknown require "team"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := config.Parse([]byte(tc.data))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	// A missing default file is not an error.
+	c, err := config.Load(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Policy) != 0 {
+		t.Errorf("expected an empty config, got %+v", c)
+	}
+
+	// A missing explicit file is.
+	if _, err := config.Load(dir, filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected error for a missing explicit config file")
+	}
+
+	data := []byte("policy:\n  - paths: [/]\n    require: owner\n")
+	if err := os.WriteFile(filepath.Join(dir, config.DefaultFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err = config.Load(dir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Policy) != 1 {
+		t.Errorf("expected 1 policy rule, got %+v", c)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("nope: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(dir, bad); err == nil || !strings.Contains(err.Error(), "bad.yaml") {
+		t.Errorf("expected error naming the file, got %v", err)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	t.Parallel()
+
+	// A missing default file is not an error.
+	c, err := config.LoadFS(fstest.MapFS{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Aliases) != 0 {
+		t.Errorf("expected an empty config, got %+v", c)
+	}
+
+	c, err = config.LoadFS(fstest.MapFS{
+		config.DefaultFile: {Data: []byte("aliases:\n  \"@payments\": [\"@org/payments\"]\n")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := c.Aliases.Expand([]string{"@payments"}); !slices.Equal(got, []string{"@org/payments"}) {
+		t.Errorf("expected the alias to expand, got %v", got)
+	}
+
+	_, err = config.LoadFS(fstest.MapFS{config.DefaultFile: {Data: []byte("nope: 1\n")}})
+	if err == nil || !strings.Contains(err.Error(), config.DefaultFile) {
+		t.Errorf("expected error naming the file, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Package diff compares the ownership of two snapshots of a repository, such
+// as the base and head of a pull request.
+package diff
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+// Snapshot is the ownership state of one tree: the mappings found by the
+// scanner and every file in the tree.
+type Snapshot struct {
+	Mappings []scanning.Mapping
+	Files    []string
+}
+
+// Status describes how a file changed between the snapshots.
+type Status string
+
+// File statuses.
+const (
+	Added    Status = "added"
+	Removed  Status = "removed"
+	Modified Status = "modified"
+)
+
+// FileChange is a file whose effective owners differ between the snapshots.
+// Base is nil for added files and Head is nil for removed ones.
+type FileChange struct {
+	Path   string
+	Status Status
+	Base   []string
+	Head   []string
+}
+
+// RuleChange is a CODEOWNERS rule that was added, removed or changed.
+type RuleChange struct {
+	Path   string
+	Status Status
+	Base   []string
+	Head   []string
+}
+
+// OwnerChange lists the files an owner gained or lost.
+type OwnerChange struct {
+	Owner  string
+	Gained []string
+	Lost   []string
+}
+
+// Report is the ownership impact of going from one snapshot to another.
+type Report struct {
+	Files  []FileChange
+	Rules  []RuleChange
+	Owners []OwnerChange
+}
+
+// Empty reports whether the snapshots have identical ownership.
+func (r Report) Empty() bool {
+	return len(r.Files) == 0 && len(r.Rules) == 0
+}
+
+// Compare computes the ownership changes from base to head. Effective owners
+// are evaluated against the CODEOWNERS file each snapshot generates.
+func Compare(base, head Snapshot) Report {
+	files := compareFiles(base, head)
+	return Report{
+		Files:  files,
+		Rules:  compareRules(base.Mappings, head.Mappings),
+		Owners: compareOwners(files),
+	}
+}
+
+func compareFiles(base, head Snapshot) []FileChange {
+	baseRules := matcher.ParseString(formatter.CodeOwners(base.Mappings))
+	headRules := matcher.ParseString(formatter.CodeOwners(head.Mappings))
+
+	inBase := make(map[string]bool, len(base.Files))
+	for _, f := range base.Files {
+		inBase[f] = true
+	}
+	inHead := make(map[string]bool, len(head.Files))
+	for _, f := range head.Files {
+		inHead[f] = true
+	}
+
+	var changes []FileChange
+	for _, f := range union(base.Files, head.Files) {
+		c := FileChange{Path: "/" + f, Status: Modified}
+		if inBase[f] {
+			c.Base = baseRules.Owners(f)
+		} else {
+			c.Status = Added
+		}
+		if inHead[f] {
+			c.Head = headRules.Owners(f)
+		} else {
+			c.Status = Removed
+		}
+		if !slices.Equal(c.Base, c.Head) {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+func compareRules(base, head []scanning.Mapping) []RuleChange {
+	baseOwners := make(map[string][]string, len(base))
+	var basePaths []string
+	for _, m := range base {
+		baseOwners[m.Path] = m.Owners
+		basePaths = append(basePaths, m.Path)
+	}
+	headOwners := make(map[string][]string, len(head))
+	var headPaths []string
+	for _, m := range head {
+		headOwners[m.Path] = m.Owners
+		headPaths = append(headPaths, m.Path)
+	}
+
+	var changes []RuleChange
+	for _, p := range union(basePaths, headPaths) {
+		b, inBase := baseOwners[p]
+		h, inHead := headOwners[p]
+		switch {
+		case !inBase:
+			changes = append(changes, RuleChange{Path: p, Status: Added, Head: h})
+		case !inHead:
+			changes = append(changes, RuleChange{Path: p, Status: Removed, Base: b})
+		case !slices.Equal(b, h):
+			changes = append(changes, RuleChange{Path: p, Status: Modified, Base: b, Head: h})
+		}
+	}
+	return changes
+}
+
+// compareOwners summarizes file changes per owner, sorted by owner.
+func compareOwners(files []FileChange) []OwnerChange {
+	byOwner := make(map[string]*OwnerChange)
+	get := func(owner string) *OwnerChange {
+		oc, ok := byOwner[owner]
+		if !ok {
+			oc = &OwnerChange{Owner: owner}
+			byOwner[owner] = oc
+		}
+		return oc
+	}
+
+	for _, f := range files {
+		for _, o := range f.Head {
+			if !slices.Contains(f.Base, o) {
+				get(o).Gained = append(get(o).Gained, f.Path)
+			}
+		}
+		for _, o := range f.Base {
+			if !slices.Contains(f.Head, o) {
+				get(o).Lost = append(get(o).Lost, f.Path)
+			}
+		}
+	}
+
+	owners := make([]OwnerChange, 0, len(byOwner))
+	for _, oc := range byOwner {
+		owners = append(owners, *oc)
+	}
+	sort.Slice(owners, func(i, j int) bool { return owners[i].Owner < owners[j].Owner })
+	return owners
+}
+
+// union returns the sorted, deduplicated elements of a and b.
+func union(a, b []string) []string {
+	out := slices.Concat(a, b)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
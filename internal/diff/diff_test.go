@@ -0,0 +1,139 @@
+package diff_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/diff"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+func testReport() diff.Report {
+	base := diff.Snapshot{
+		Mappings: []scanning.Mapping{
+			{Path: "/api/", Owners: []string{"@api-team"}},
+			{Path: "/api/handler.go", Owners: []string{"@handler-team"}},
+			{Path: "/old.go", Owners: []string{"@legacy"}},
+		},
+		Files: []string{"api/.codeowner", "api/handler.go", "api/util.go", "old.go", "README.md"},
+	}
+	head := diff.Snapshot{
+		Mappings: []scanning.Mapping{
+			{Path: "/api/", Owners: []string{"@platform"}},
+			{Path: "/api/handler.go", Owners: []string{"@handler-team"}},
+			{Path: "/web/index.html", Owners: []string{"@frontend"}},
+		},
+		Files: []string{"api/.codeowner", "api/handler.go", "api/util.go", "web/index.html", "README.md"},
+	}
+	return diff.Compare(base, head)
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	r := testReport()
+
+	wantFiles := []diff.FileChange{
+		{Path: "/api/.codeowner", Status: diff.Modified, Base: []string{"@api-team"}, Head: []string{"@platform"}},
+		{Path: "/api/util.go", Status: diff.Modified, Base: []string{"@api-team"}, Head: []string{"@platform"}},
+		{Path: "/old.go", Status: diff.Removed, Base: []string{"@legacy"}},
+		{Path: "/web/index.html", Status: diff.Added, Head: []string{"@frontend"}},
+	}
+	if !slices.EqualFunc(r.Files, wantFiles, func(a, b diff.FileChange) bool {
+		return a.Path == b.Path && a.Status == b.Status && slices.Equal(a.Base, b.Base) && slices.Equal(a.Head, b.Head)
+	}) {
+		t.Errorf("Files:\ngot:  %v\nwant: %v", r.Files, wantFiles)
+	}
+
+	wantRules := []diff.RuleChange{
+		{Path: "/api/", Status: diff.Modified, Base: []string{"@api-team"}, Head: []string{"@platform"}},
+		{Path: "/old.go", Status: diff.Removed, Base: []string{"@legacy"}},
+		{Path: "/web/index.html", Status: diff.Added, Head: []string{"@frontend"}},
+	}
+	if !slices.EqualFunc(r.Rules, wantRules, func(a, b diff.RuleChange) bool {
+		return a.Path == b.Path && a.Status == b.Status && slices.Equal(a.Base, b.Base) && slices.Equal(a.Head, b.Head)
+	}) {
+		t.Errorf("Rules:\ngot:  %v\nwant: %v", r.Rules, wantRules)
+	}
+
+	var owners []string
+	for _, o := range r.Owners {
+		owners = append(owners, o.Owner)
+	}
+	if want := []string{"@api-team", "@frontend", "@legacy", "@platform"}; !slices.Equal(owners, want) {
+		t.Errorf("Owners = %v, want %v", owners, want)
+	}
+	if got := r.Owners[3].Gained; len(got) != 2 {
+		t.Errorf("@platform should gain 2 files, got %v", got)
+	}
+}
+
+func TestCompare_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	snap := diff.Snapshot{
+		Mappings: []scanning.Mapping{{Path: "/main.go", Owners: []string{"@a"}}},
+		Files:    []string{"main.go", "other.go"},
+	}
+	r := diff.Compare(snap, snap)
+	if !r.Empty() {
+		t.Errorf("expected empty report, got %+v", r)
+	}
+
+	var buf bytes.Buffer
+	if err := diff.WriteText(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "No ownership changes.\n" {
+		t.Errorf("WriteText = %q", buf.String())
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := diff.WriteText(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"Files with changed owners:\n",
+		"  M /api/util.go: @api-team -> @platform\n",
+		"  D /old.go: @legacy -> (none)\n",
+		"  A /web/index.html: (none) -> @frontend\n",
+		"Rules:\n",
+		"  M /api/: @api-team -> @platform\n",
+		"Owners:\n",
+		"  @platform: gained 2, lost 0\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := diff.WriteMarkdown(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"## Ownership changes\n",
+		"| modified | `/api/util.go` | `@api-team` | `@platform` |\n",
+		"| added | `/web/index.html` | _none_ | `@frontend` |\n",
+		"### Rules\n",
+		"| `@legacy` | 0 | 1 |\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q, got:\n%s", want, got)
+		}
+	}
+}
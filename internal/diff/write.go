@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText writes the report in a plain text form for terminals.
+func WriteText(w io.Writer, r Report) error {
+	if r.Empty() {
+		_, err := io.WriteString(w, "No ownership changes.\n")
+		return err
+	}
+
+	var b strings.Builder
+	if len(r.Files) > 0 {
+		b.WriteString("Files with changed owners:\n")
+		for _, f := range r.Files {
+			fmt.Fprintf(&b, "  %s %s: %s -> %s\n", statusMark(f.Status), f.Path, ownersText(f.Base), ownersText(f.Head))
+		}
+	}
+	if len(r.Rules) > 0 {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("Rules:\n")
+		for _, c := range r.Rules {
+			fmt.Fprintf(&b, "  %s %s: %s -> %s\n", statusMark(c.Status), c.Path, ownersText(c.Base), ownersText(c.Head))
+		}
+	}
+	if len(r.Owners) > 0 {
+		b.WriteString("\nOwners:\n")
+		for _, o := range r.Owners {
+			fmt.Fprintf(&b, "  %s: gained %d, lost %d\n", o.Owner, len(o.Gained), len(o.Lost))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMarkdown writes the report as Markdown suitable for a pull request
+// comment. Owners are wrapped in code spans so that pasting the report does
+// not notify them.
+func WriteMarkdown(w io.Writer, r Report) error {
+	var b strings.Builder
+	b.WriteString("## Ownership changes\n\n")
+	if r.Empty() {
+		b.WriteString("No ownership changes.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	if len(r.Files) > 0 {
+		b.WriteString("### Files\n\n| Status | File | Before | After |\n| --- | --- | --- | --- |\n")
+		for _, f := range r.Files {
+			fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", f.Status, f.Path, ownersMarkdown(f.Base), ownersMarkdown(f.Head))
+		}
+	}
+	if len(r.Rules) > 0 {
+		b.WriteString("\n### Rules\n\n| Status | Rule | Before | After |\n| --- | --- | --- | --- |\n")
+		for _, c := range r.Rules {
+			fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", c.Status, c.Path, ownersMarkdown(c.Base), ownersMarkdown(c.Head))
+		}
+	}
+	if len(r.Owners) > 0 {
+		b.WriteString("\n### Owners\n\n| Owner | Files gained | Files lost |\n| --- | --- | --- |\n")
+		for _, o := range r.Owners {
+			fmt.Fprintf(&b, "| `%s` | %d | %d |\n", o.Owner, len(o.Gained), len(o.Lost))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func statusMark(s Status) string {
+	switch s {
+	case Added:
+		return "A"
+	case Removed:
+		return "D"
+	case Modified:
+		return "M"
+	default:
+		return "?"
+	}
+}
+
+func ownersText(owners []string) string {
+	if len(owners) == 0 {
+		return "(none)"
+	}
+	return strings.Join(owners, " ")
+}
+
+func ownersMarkdown(owners []string) string {
+	if len(owners) == 0 {
+		return "_none_"
+	}
+	return "`" + strings.Join(owners, "` `") + "`"
+}
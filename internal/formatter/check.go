@@ -0,0 +1,62 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+)
+
+// MaxSize is the largest CODEOWNERS file GitHub reads, in bytes. Larger
+// files are ignored entirely.
+const MaxSize = 3 << 20
+
+// Problem is a reason GitHub would ignore part or all of a CODEOWNERS file.
+// Line is 0 for problems with the file as a whole.
+type Problem struct {
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// Check reports the problems GitHub would find in the CODEOWNERS file s:
+// exceeding MaxSize, and patterns using syntax GitHub does not support.
+func Check(s string) []Problem {
+	var problems []Problem
+	if len(s) > MaxSize {
+		problems = append(problems, Problem{
+			Message: fmt.Sprintf("file is %d bytes, GitHub ignores CODEOWNERS files larger than %d bytes", len(s), MaxSize),
+		})
+	}
+	for _, r := range matcher.ParseString(s) {
+		if msg := checkPattern(r.Pattern); msg != "" {
+			problems = append(problems, Problem{Line: r.Line, Message: fmt.Sprintf("%s: %s", r.Pattern, msg)})
+		}
+	}
+	return problems
+}
+
+// checkPattern returns why GitHub rejects pattern, or "" if it does not.
+func checkPattern(pattern string) string {
+	switch {
+	case strings.HasPrefix(pattern, `\#`):
+		return "escaping # to start a pattern is not supported"
+	case strings.HasPrefix(pattern, "!"):
+		return "negating a pattern with ! is not supported"
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			return "character ranges with [ ] are not supported"
+		}
+	}
+	return ""
+}
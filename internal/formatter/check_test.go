@@ -0,0 +1,70 @@
+package formatter_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "valid",
+			input: "CODEOWNERS @admin\n\n# comment\n/ @all\n/src/*.go @go\n/docs/\\[draft\\].md @docs\n",
+		},
+		{
+			name:  "escaped hash",
+			input: "/ok @a\n\\#notes.txt @b\n",
+			want:  []string{`line 2: \#notes.txt: escaping # to start a pattern is not supported`},
+		},
+		{
+			name:  "negation",
+			input: "!/vendor/ @a\n",
+			want:  []string{"line 1: !/vendor/: negating a pattern with ! is not supported"},
+		},
+		{
+			name:  "character range",
+			input: "/src/[ab].go @a\n/src/x.go @b\n/lib/v[0-9]/ @c\n",
+			want: []string{
+				"line 1: /src/[ab].go: character ranges with [ ] are not supported",
+				"line 3: /lib/v[0-9]/: character ranges with [ ] are not supported",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, p := range formatter.Check(tc.input) {
+				got = append(got, p.String())
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Check:\ngot:  %q\nwant: %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheck_Size(t *testing.T) {
+	t.Parallel()
+
+	line := "/src/file.go @team\n"
+	big := strings.Repeat(line, formatter.MaxSize/len(line)+1)
+	problems := formatter.Check(big)
+	if len(problems) != 1 || problems[0].Line != 0 || !strings.Contains(problems[0].Message, "larger than") {
+		t.Errorf("Check of %d bytes: got %v, want one size problem", len(big), problems)
+	}
+	if problems := formatter.Check(big[:formatter.MaxSize]); len(problems) != 0 {
+		t.Errorf("Check of exactly MaxSize bytes: got %v, want none", problems)
+	}
+}
@@ -0,0 +1,150 @@
+package formatter
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+// Collapse replaces the rules inside a directory with a single rule for the
+// directory when every file in it, recursively, has the same owners, and
+// doing so removes rules. The directory rule keeps the comments of the rules
+// it replaces. files lists every file of the tree as a
+// slash-separated path relative to the root.
+//
+// The effective owners of every file under the result are checked against
+// those under mappings with the matcher; if any differ, mappings is returned
+// unchanged.
+func Collapse(mappings []scanning.Mapping, files []string) []scanning.Mapping {
+	before := matcher.ParseString(CodeOwners(mappings))
+	owners := make(map[string]string, len(files))
+	for _, f := range files {
+		owners[f] = strings.Join(before.Owners(f), " ")
+	}
+
+	var protect, rules []scanning.Mapping
+	for _, m := range mappings {
+		if m.Path == "CODEOWNERS" {
+			protect = append(protect, m)
+		} else {
+			rules = append(rules, m)
+		}
+	}
+
+	c := collapser{before: before, owners: owners}
+	out := append(protect, c.collapse("", rules, slices.Sorted(slices.Values(files)))...)
+
+	after := matcher.ParseString(CodeOwners(out))
+	for _, f := range files {
+		if strings.Join(after.Owners(f), " ") != owners[f] {
+			return mappings
+		}
+	}
+	return out
+}
+
+// collapser holds the effective owners of every file before collapsing.
+type collapser struct {
+	before matcher.Ruleset
+	owners map[string]string // file -> space-separated owners
+}
+
+// collapse returns the rules for the directory dir ("" for the root), given
+// the rules and files inside it.
+func (c collapser) collapse(dir string, rules []scanning.Mapping, files []string) []scanning.Mapping {
+	if len(files) > 0 && c.uniform(files) {
+		if len(rules) < 2 {
+			return rules
+		}
+		return []scanning.Mapping{{Path: dirPath(dir), Owners: c.before.Owners(files[0]), Comments: comments(rules)}}
+	}
+
+	// Keep the rules for dir itself and its files, and collapse each
+	// subdirectory on its own.
+	var out []scanning.Mapping
+	var subdirs []string
+	seen := make(map[string]bool)
+	subRules := make(map[string][]scanning.Mapping)
+	subFiles := make(map[string][]string)
+	addSubdir := func(sub string) {
+		if !seen[sub] {
+			seen[sub] = true
+			subdirs = append(subdirs, sub)
+		}
+	}
+	for _, m := range rules {
+		sub, ok := child(dir, strings.Trim(m.Path, "/"), strings.HasSuffix(m.Path, "/"))
+		if !ok {
+			out = append(out, m)
+			continue
+		}
+		addSubdir(sub)
+		subRules[sub] = append(subRules[sub], m)
+	}
+	for _, f := range files {
+		if sub, ok := child(dir, f, false); ok {
+			addSubdir(sub)
+			subFiles[sub] = append(subFiles[sub], f)
+		}
+	}
+	for _, sub := range subdirs {
+		out = append(out, c.collapse(sub, subRules[sub], subFiles[sub])...)
+	}
+	return out
+}
+
+// comments returns the distinct comments of rules, in order.
+func comments(rules []scanning.Mapping) []string {
+	var out []string
+	for _, m := range rules {
+		for _, c := range m.Comments {
+			if !slices.Contains(out, c) {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// uniform reports whether every file has the same, non-empty owners.
+func (c collapser) uniform(files []string) bool {
+	first := c.owners[files[0]]
+	if first == "" {
+		return false
+	}
+	for _, f := range files[1:] {
+		if c.owners[f] != first {
+			return false
+		}
+	}
+	return true
+}
+
+// child returns the subdirectory of dir that contains p, a path inside dir,
+// or false if p is dir itself or a file directly in it. isDir tells whether
+// p names a directory.
+func child(dir, p string, isDir bool) (string, bool) {
+	if p == dir {
+		return "", false
+	}
+	rel := p
+	if dir != "" {
+		rel = strings.TrimPrefix(p, dir+"/")
+	}
+	first, _, nested := strings.Cut(rel, "/")
+	if !nested && !isDir {
+		return "", false
+	}
+	return path.Join(dir, first), true
+}
+
+// dirPath returns the CODEOWNERS pattern for a directory.
+func dirPath(dir string) string {
+	if dir == "" {
+		return "/"
+	}
+	return "/" + dir + "/"
+}
@@ -0,0 +1,121 @@
+package formatter_test
+
+import (
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+func TestCollapse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		mappings []scanning.Mapping
+		files    []string
+		want     string
+	}{
+		{
+			name: "sibling files with the same owners",
+			mappings: []scanning.Mapping{
+				{Path: "/api/a.go", Owners: []string{"@backend"}},
+				{Path: "/api/b.go", Owners: []string{"@backend"}},
+				{Path: "/README.md", Owners: []string{"@docs"}},
+			},
+			files: []string{"api/a.go", "api/b.go", "README.md"},
+			want:  "/README.md @docs\n\n/api/ @backend\n",
+		},
+		{
+			name: "unannotated file blocks collapse",
+			mappings: []scanning.Mapping{
+				{Path: "/api/a.go", Owners: []string{"@backend"}},
+				{Path: "/api/b.go", Owners: []string{"@backend"}},
+			},
+			files: []string{"api/a.go", "api/b.go", "api/c.go"},
+			want:  "/api/a.go @backend\n/api/b.go @backend\n",
+		},
+		{
+			name: "different owners block collapse",
+			mappings: []scanning.Mapping{
+				{Path: "/api/a.go", Owners: []string{"@backend"}},
+				{Path: "/api/b.go", Owners: []string{"@backend", "@sre"}},
+			},
+			files: []string{"api/a.go", "api/b.go"},
+			want:  "/api/a.go @backend\n/api/b.go @backend @sre\n",
+		},
+		{
+			name: "recursive with nested directory rule",
+			mappings: []scanning.Mapping{
+				{Path: "/svc/", Owners: []string{"@svc"}},
+				{Path: "/svc/main.go", Owners: []string{"@svc"}},
+				{Path: "/svc/internal/db/", Owners: []string{"@svc"}},
+				{Path: "/svc/internal/db/conn.go", Owners: []string{"@svc"}},
+			},
+			files: []string{"README.md", "svc/main.go", "svc/util.go", "svc/internal/db/conn.go", "svc/internal/db/pool.go"},
+			want:  "/svc/ @svc\n",
+		},
+		{
+			name: "subdirectory collapses when parent cannot",
+			mappings: []scanning.Mapping{
+				{Path: "/web/", Owners: []string{"@frontend"}},
+				{Path: "/web/api/a.ts", Owners: []string{"@api"}},
+				{Path: "/web/api/b.ts", Owners: []string{"@api"}},
+			},
+			files: []string{"web/index.html", "web/api/a.ts", "web/api/b.ts"},
+			want:  "/web/ @frontend\n\n/web/api/ @api\n",
+		},
+		{
+			name: "whole tree",
+			mappings: []scanning.Mapping{
+				{Path: "CODEOWNERS", Owners: []string{"@admin"}},
+				{Path: "/", Owners: []string{"@all"}},
+				{Path: "/a.go", Owners: []string{"@all"}},
+				{Path: "/x/b.go", Owners: []string{"@all"}},
+			},
+			files: []string{"a.go", "x/b.go"},
+			want:  "CODEOWNERS @admin\n\n/ @all\n",
+		},
+		{
+			name: "comments are kept",
+			mappings: []scanning.Mapping{
+				{Path: "/deploy/a.sh", Owners: []string{"@sre"}, Comments: []string{"on-call"}},
+				{Path: "/deploy/b.sh", Owners: []string{"@sre"}, Comments: []string{"on-call"}},
+				{Path: "/deploy/c.sh", Owners: []string{"@sre"}, Comments: []string{"release tooling"}},
+				{Path: "/main.go", Owners: []string{"@backend"}},
+			},
+			files: []string{"main.go", "deploy/a.sh", "deploy/b.sh", "deploy/c.sh"},
+			want:  "/main.go @backend\n\n# on-call\n# release tooling\n/deploy/ @sre\n",
+		},
+		{
+			name: "single rule is kept",
+			mappings: []scanning.Mapping{
+				{Path: "/docs/guide.md", Owners: []string{"@docs"}},
+			},
+			files: []string{"docs/guide.md"},
+			want:  "/docs/guide.md @docs\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := formatter.CodeOwners(formatter.Collapse(tc.mappings, tc.files))
+			if got != tc.want {
+				t.Errorf("Collapse:\ngot:\n%s\nwant:\n%s", got, tc.want)
+			}
+
+			// Effective ownership must be unchanged for every file.
+			before := matcher.ParseString(formatter.CodeOwners(tc.mappings))
+			after := matcher.ParseString(got)
+			for _, f := range tc.files {
+				b, a := before.Owners(f), after.Owners(f)
+				if len(a) != len(b) {
+					t.Errorf("%s: owners changed from %v to %v", f, b, a)
+				}
+			}
+		})
+	}
+}
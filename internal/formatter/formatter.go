@@ -4,52 +4,208 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/kevin-robayna/codeowner/internal/scanning"
 )
 
+// DefaultGroupDepth is the number of leading directories rules are grouped
+// by unless Options say otherwise.
+const DefaultGroupDepth = 2
+
+// GroupBy selects what rules are grouped by.
+type GroupBy string
+
+const (
+	// GroupByPath groups rules by their leading directories.
+	GroupByPath GroupBy = "path"
+	// GroupByOwner groups rules with the same owners.
+	GroupByOwner GroupBy = "owner"
+)
+
+// Options controls the layout of a CODEOWNERS file. The zero value groups
+// rules by path without looking at directories, so every rule of a section
+// forms one group.
+type Options struct {
+	// GroupBy selects what rules are grouped by. Defaults to GroupByPath.
+	GroupBy GroupBy
+	// GroupDepth is the number of leading directories that group rules
+	// by path.
+	GroupDepth int
+	// GroupComments adds a "# group" comment naming each group above it.
+	GroupComments bool
+	// Align pads patterns so the owners of every rule in a group start in the
+	// same column.
+	Align bool
+	// Header, if set, is written as a comment block before the rules.
+	Header *Header
+}
+
 // CodeOwners formats mappings as a GitHub CODEOWNERS file.
 // Output is sorted and grouped: root files first, then hidden-directory files,
 // then everything else. Within each section, entries are grouped by their
 // top-2-level directory with blank lines between groups.
 func CodeOwners(mappings []scanning.Mapping) string {
+	return Format(mappings, Options{GroupDepth: DefaultGroupDepth})
+}
+
+// Format formats mappings as a GitHub CODEOWNERS file laid out as opts
+// describe. Groups are separated by blank lines, and the comments of a
+// mapping are written above its rule.
+//
+// Grouping by path sorts root files first, then hidden-directory files,
+// then everything else, and groups each section by the first
+// opts.GroupDepth directories.
+//
+// Grouping by owner sorts rules by owners, except that a directory rule
+// always comes before the rules for paths inside it, which would otherwise
+// lose to it as the last matching rule. Rules nested in more directory
+// rules therefore form later groups.
+func Format(mappings []scanning.Mapping, opts Options) string {
+	var b strings.Builder
+	if opts.Header != nil {
+		opts.Header.write(&b)
+	}
+	for i, g := range arrange(mappings, opts) {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		writeGroup(&b, g, opts)
+	}
+	return b.String()
+}
+
+// arrange sorts mappings into the groups Format writes, in order. The rule
+// protecting the CODEOWNERS file, if any, forms the first group.
+func arrange(mappings []scanning.Mapping, opts Options) [][]rule {
 	var protect *scanning.Mapping
-	sorted := make([]scanning.Mapping, 0, len(mappings))
+	rules := make([]rule, 0, len(mappings))
 	for i := range mappings {
 		if mappings[i].Path == "CODEOWNERS" {
 			protect = &mappings[i]
 		} else {
-			sorted = append(sorted, mappings[i])
+			rules = append(rules, rule{Mapping: mappings[i], pattern: escapePath(mappings[i].Path)})
 		}
 	}
 
-	sort.Slice(sorted, func(i, j int) bool {
-		si, sj := pathSection(sorted[i].Path), pathSection(sorted[j].Path)
-		if si != sj {
-			return si < sj
+	if opts.GroupBy == GroupByOwner {
+		groupByOwner(rules)
+	} else {
+		groupByPath(rules, opts.GroupDepth)
+	}
+	sortRules(rules)
+
+	groups := splitGroups(rules)
+	if protect != nil {
+		p := rule{Mapping: *protect, pattern: escapePath(protect.Path)}
+		groups = append([][]rule{{p}}, groups...)
+	}
+	return groups
+}
+
+// sortRules sorts rules by section, then group, then path.
+func sortRules(rules []rule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].section != rules[j].section {
+			return rules[i].section < rules[j].section
 		}
-		gi, gj := groupKey(sorted[i].Path), groupKey(sorted[j].Path)
-		if gi != gj {
-			return gi < gj
+		if rules[i].group != rules[j].group {
+			return rules[i].group < rules[j].group
 		}
-		return sorted[i].Path < sorted[j].Path
+		return rules[i].Path < rules[j].Path
 	})
+}
 
-	var b strings.Builder
-	if protect != nil {
-		fmt.Fprintf(&b, "%s %s\n", protect.Path, strings.Join(protect.Owners, " "))
-		if len(sorted) > 0 {
-			b.WriteByte('\n')
+// splitGroups splits sorted rules into runs of the same section and group.
+func splitGroups(rules []rule) [][]rule {
+	var groups [][]rule
+	for i, r := range rules {
+		if i == 0 || r.section != rules[i-1].section || r.group != rules[i-1].group {
+			groups = append(groups, nil)
 		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], r)
 	}
-	prevGroup := ""
-	for i, m := range sorted {
-		g := groupKey(m.Path)
-		if i > 0 && g != prevGroup {
-			b.WriteByte('\n')
+	return groups
+}
+
+// writeGroup writes the rules of one group, with their comments above them.
+func writeGroup(b *strings.Builder, g []rule, opts Options) {
+	if opts.GroupComments && g[0].title != "" {
+		fmt.Fprintf(b, "# %s\n", g[0].title)
+	}
+	width := 0
+	if opts.Align {
+		for _, r := range g {
+			width = max(width, utf8.RuneCountInString(r.pattern))
+		}
+	}
+	for _, r := range g {
+		for _, c := range r.Comments {
+			fmt.Fprintf(b, "# %s\n", c)
+		}
+		pad := max(width-utf8.RuneCountInString(r.pattern), 0)
+		fmt.Fprintf(b, "%s%s %s\n", r.pattern, strings.Repeat(" ", pad), strings.Join(r.Owners, " "))
+	}
+}
+
+// rule is a mapping with its escaped pattern and the group it is placed in.
+type rule struct {
+	scanning.Mapping
+	pattern string
+	section int
+	group   string
+	title   string
+}
+
+// groupByPath places each rule in its path section and directory group.
+func groupByPath(rules []rule, depth int) {
+	for i := range rules {
+		r := &rules[i]
+		r.section = pathSection(r.Path)
+		r.group = groupKey(r.Path, depth)
+		r.title = "/" + r.group
+		if r.group != "" {
+			r.title += "/"
+		}
+	}
+}
+
+// groupByOwner places each rule in a group for its owners, in a section
+// for the number of directory rules containing it.
+func groupByOwner(rules []rule) {
+	var dirs []string
+	for _, r := range rules {
+		if strings.HasSuffix(r.Path, "/") {
+			dirs = append(dirs, r.Path)
+		}
+	}
+	for i := range rules {
+		r := &rules[i]
+		for _, d := range dirs {
+			if d != r.Path && strings.HasPrefix(r.Path, d) {
+				r.section++
+			}
 		}
-		prevGroup = g
-		fmt.Fprintf(&b, "%s %s\n", m.Path, strings.Join(m.Owners, " "))
+		r.group = strings.Join(r.Owners, " ")
+		r.title = r.group
+	}
+}
+
+// escapePath escapes the characters CODEOWNERS patterns give a special
+// meaning with a backslash, so that the pattern for a path matches exactly
+// that path: whitespace ends the pattern, # starts a comment, and *, ? and
+// [ are wildcards.
+func escapePath(path string) string {
+	if !strings.ContainsAny(path, " \t#*?[\\") {
+		return path
+	}
+	var b strings.Builder
+	for _, r := range path {
+		switch r {
+		case ' ', '\t', '#', '*', '?', '[', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
 	}
 	return b.String()
 }
@@ -74,21 +230,13 @@ func pathSection(path string) int {
 }
 
 // groupKey returns the grouping key for a path based on its directory
-// structure: "" for root files, the first directory for single-depth paths,
-// or the first two directories for deeper paths.
-func groupKey(path string) string {
+// structure: "" for root files, or its first depth directories.
+func groupKey(path string, depth int) string {
 	p := stripRoot(path)
 	idx := strings.LastIndex(p, "/")
-	if idx < 0 {
+	if idx <= 0 || depth <= 0 {
 		return ""
 	}
-	dir := p[:idx]
-	if dir == "" {
-		return ""
-	}
-	parts := strings.SplitN(dir, "/", 3)
-	if len(parts) >= 2 {
-		return parts[0] + "/" + parts[1]
-	}
-	return parts[0]
+	parts := strings.Split(p[:idx], "/")
+	return strings.Join(parts[:min(depth, len(parts))], "/")
 }
@@ -1,9 +1,11 @@
 package formatter_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/matcher"
 	"github.com/kevin-robayna/codeowner/internal/scanning"
 )
 
@@ -144,3 +146,155 @@ func TestCodeOwners_SameDirectoryNoBlankLines(t *testing.T) {
 		t.Errorf("CodeOwners:\ngot:\n%s\nwant:\n%s", got, want)
 	}
 }
+
+func TestCodeOwners_EscapesSpecialCharacters(t *testing.T) {
+	t.Parallel()
+
+	// Each name sits next to files its pattern would also match if the
+	// special characters were not escaped.
+	files := []string{
+		"a b.go", "a",
+		"a*.go", "ab.go", "a.go",
+		"?.go", "x.go",
+		"[ab].go",
+		"#notes", "notes",
+		`back\slash`, "backslash",
+		"tab\tname",
+		"dir with space/file.go", "dir/file.go",
+		"docs/*", "docs/guide.md",
+		"trailing ",
+	}
+
+	mappings := make([]scanning.Mapping, len(files))
+	for i, f := range files {
+		mappings[i] = scanning.Mapping{Path: "/" + f, Owners: []string{fmt.Sprintf("@team%d", i)}}
+	}
+	out := formatter.CodeOwners(mappings)
+	rules := matcher.ParseString(out)
+	if len(rules) != len(files) {
+		t.Fatalf("got %d rules, want %d:\n%s", len(rules), len(files), out)
+	}
+
+	for _, r := range rules {
+		var matched []string
+		for _, f := range files {
+			if matcher.MatchPattern(r.Pattern, f) {
+				matched = append(matched, f)
+			}
+		}
+		if len(matched) != 1 {
+			t.Errorf("pattern %q matches %q, want exactly one file", r.Pattern, matched)
+		}
+	}
+	for i, f := range files {
+		if got := rules.Owners(f); len(got) != 1 || got[0] != mappings[i].Owners[0] {
+			t.Errorf("%q: owners %v, want %v", f, got, mappings[i].Owners)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	mappings := []scanning.Mapping{
+		{Path: "CODEOWNERS", Owners: []string{"@admin"}},
+		{Path: "/README.md", Owners: []string{"@docs"}},
+		{Path: "/src/", Owners: []string{"@backend"}},
+		{Path: "/src/api/handler.go", Owners: []string{"@api"}},
+		{Path: "/src/cmd/main.go", Owners: []string{"@backend"}},
+		{Path: "/web/index.html", Owners: []string{"@docs"}},
+	}
+
+	testCases := []struct {
+		name string
+		opts formatter.Options
+		want string
+	}{
+		{
+			name: "group depth 1",
+			opts: formatter.Options{GroupDepth: 1},
+			want: "CODEOWNERS @admin\n\n" +
+				"/README.md @docs\n\n" +
+				"/src/ @backend\n/src/api/handler.go @api\n/src/cmd/main.go @backend\n\n" +
+				"/web/index.html @docs\n",
+		},
+		{
+			name: "group depth 0",
+			opts: formatter.Options{},
+			want: "CODEOWNERS @admin\n\n" +
+				"/README.md @docs\n\n" +
+				"/src/ @backend\n/src/api/handler.go @api\n/src/cmd/main.go @backend\n/web/index.html @docs\n",
+		},
+		{
+			name: "group comments",
+			opts: formatter.Options{GroupDepth: 1, GroupComments: true},
+			want: "CODEOWNERS @admin\n\n" +
+				"# /\n/README.md @docs\n\n" +
+				"# /src/\n/src/ @backend\n/src/api/handler.go @api\n/src/cmd/main.go @backend\n\n" +
+				"# /web/\n/web/index.html @docs\n",
+		},
+		{
+			name: "align",
+			opts: formatter.Options{GroupDepth: 1, Align: true},
+			want: "CODEOWNERS @admin\n\n" +
+				"/README.md @docs\n\n" +
+				"/src/               @backend\n/src/api/handler.go @api\n/src/cmd/main.go    @backend\n\n" +
+				"/web/index.html @docs\n",
+		},
+		{
+			// The rules inside /src/ follow it even though @api sorts first.
+			name: "group by owner",
+			opts: formatter.Options{GroupBy: formatter.GroupByOwner, GroupComments: true},
+			want: "CODEOWNERS @admin\n\n" +
+				"# @backend\n/src/ @backend\n\n" +
+				"# @docs\n/README.md @docs\n/web/index.html @docs\n\n" +
+				"# @api\n/src/api/handler.go @api\n\n" +
+				"# @backend\n/src/cmd/main.go @backend\n",
+		},
+	}
+
+	want := matcher.ParseString(formatter.CodeOwners(mappings))
+	files := []string{"README.md", "src/api/handler.go", "src/cmd/main.go", "src/other.go", "web/index.html"}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := formatter.Format(mappings, tc.opts)
+			if got != tc.want {
+				t.Errorf("Format:\ngot:\n%s\nwant:\n%s", got, tc.want)
+			}
+
+			// The layout never changes who owns a file.
+			rules := matcher.ParseString(got)
+			for _, f := range files {
+				if g, w := fmt.Sprint(rules.Owners(f)), fmt.Sprint(want.Owners(f)); g != w {
+					t.Errorf("%s: owners %s, want %s", f, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestCodeOwners_Comments(t *testing.T) {
+	t.Parallel()
+
+	mappings := []scanning.Mapping{
+		{Path: "/deploy/run.sh", Owners: []string{"@sre-team"}, Comments: []string{"on-call rotation owns deploy scripts"}},
+		{Path: "/deploy/", Owners: []string{"@platform"}, Comments: []string{"shared tooling", "ask in #platform"}},
+		{Path: "/main.go", Owners: []string{"@backend"}},
+	}
+
+	got := formatter.CodeOwners(mappings)
+	want := "/main.go @backend\n" +
+		"\n" +
+		"# shared tooling\n" +
+		"# ask in #platform\n" +
+		"/deploy/ @platform\n" +
+		"# on-call rotation owns deploy scripts\n" +
+		"/deploy/run.sh @sre-team\n"
+
+	if got != want {
+		t.Errorf("CodeOwners:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// headerNotice is the first line of a generated header. It follows the Go
+// convention for generated files, which editors and review tools recognise.
+const headerNotice = "# Code generated by codeowner. DO NOT EDIT."
+
+// Header describes how a CODEOWNERS file was generated, so that readers know
+// to regenerate it rather than edit it by hand.
+type Header struct {
+	// Version is the codeowner version that generated the file.
+	Version string
+	// Command is the command line that regenerates the file.
+	Command string
+	// Config is the configuration file used, or "" if there was none.
+	Config string
+}
+
+// write writes the header as a comment block followed by a blank line.
+func (h *Header) write(b *strings.Builder) {
+	b.WriteString(headerNotice + "\n")
+	b.WriteString("# Change the CodeOwner annotations instead and regenerate it with:\n")
+	fmt.Fprintf(b, "#   %s\n", h.Command)
+	fmt.Fprintf(b, "# Version: %s\n", h.Version)
+	if h.Config != "" {
+		fmt.Fprintf(b, "# Config: %s\n", h.Config)
+	}
+	b.WriteByte('\n')
+}
+
+// StripHeader returns s without the header written for Options.Header, if
+// it starts with one, so that files generated by different versions or
+// command lines can be compared by their rules alone.
+func StripHeader(s string) string {
+	if !strings.HasPrefix(s, headerNotice+"\n") {
+		return s
+	}
+	rest := s
+	for strings.HasPrefix(rest, "#") {
+		_, rest, _ = strings.Cut(rest, "\n")
+	}
+	return strings.TrimPrefix(rest, "\n")
+}
@@ -0,0 +1,71 @@
+package formatter_test
+
+import (
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+func TestFormat_Header(t *testing.T) {
+	t.Parallel()
+
+	mappings := []scanning.Mapping{{Path: "/main.go", Owners: []string{"@backend"}}}
+
+	testCases := []struct {
+		name   string
+		header formatter.Header
+		want   string
+	}{
+		{
+			name:   "with config",
+			header: formatter.Header{Version: "v1.2.0", Command: "codeowner --collapse .", Config: ".codeowner.yaml"},
+			want: "# Code generated by codeowner. DO NOT EDIT.\n" +
+				"# Change the CodeOwner annotations instead and regenerate it with:\n" +
+				"#   codeowner --collapse .\n" +
+				"# Version: v1.2.0\n" +
+				"# Config: .codeowner.yaml\n" +
+				"\n" +
+				"/main.go @backend\n",
+		},
+		{
+			name:   "without config",
+			header: formatter.Header{Version: "dev", Command: "codeowner"},
+			want: "# Code generated by codeowner. DO NOT EDIT.\n" +
+				"# Change the CodeOwner annotations instead and regenerate it with:\n" +
+				"#   codeowner\n" +
+				"# Version: dev\n" +
+				"\n" +
+				"/main.go @backend\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := formatter.Format(mappings, formatter.Options{Header: &tc.header})
+			if got != tc.want {
+				t.Errorf("Format:\ngot:\n%s\nwant:\n%s", got, tc.want)
+			}
+			if stripped := formatter.StripHeader(got); stripped != formatter.CodeOwners(mappings) {
+				t.Errorf("StripHeader:\ngot:\n%s\nwant:\n%s", stripped, formatter.CodeOwners(mappings))
+			}
+		})
+	}
+}
+
+func TestStripHeader_KeepsOtherComments(t *testing.T) {
+	t.Parallel()
+
+	// Comments that are not a generated header are part of the file.
+	for _, s := range []string{
+		"# hand-written\n\n/main.go @backend\n",
+		"/main.go @backend\n# Code generated by codeowner. DO NOT EDIT.\n",
+		"",
+	} {
+		if got := formatter.StripHeader(s); got != s {
+			t.Errorf("StripHeader(%q) = %q, want it unchanged", s, got)
+		}
+	}
+}
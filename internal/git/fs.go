@@ -0,0 +1,359 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLinkHops bounds the number of symlinks resolved when opening a path.
+const maxLinkHops = 255
+
+// FS is a read-only fs.FS over a snapshot of a git repository, such as a
+// commit's tree or the index. File contents are read lazily from the object
+// store. Call Close to release the git process used to read them.
+type FS struct {
+	dir     string
+	entries map[string]*entry // every file and directory by slash path
+	blobs   *blobReader
+}
+
+// entry describes a file or directory in the snapshot.
+type entry struct {
+	name     string
+	mode     fs.FileMode
+	oid      string // blob object id, empty for directories
+	size     int64
+	children []*entry
+}
+
+// TreeFS returns the tree of rev as seen from dir. Paths are relative to dir,
+// so a subdirectory of the repository yields only that part of the tree.
+// Submodules are omitted.
+func TreeFS(ctx context.Context, dir, rev string) (*FS, error) {
+	out, err := Run(ctx, dir, "ls-tree", "-r", "-z", "-l", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := newFS(ctx, dir)
+	for _, rec := range splitZ(out) {
+		// <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		meta, name, ok := strings.Cut(rec, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 {
+			return nil, fmt.Errorf("git ls-tree: unexpected output %q", rec)
+		}
+		if fields[1] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("git ls-tree: unexpected size in %q", rec)
+		}
+		fsys.add(name, fields[0], fields[2], size)
+	}
+	return fsys, nil
+}
+
+func newFS(ctx context.Context, dir string) *FS {
+	return &FS{
+		dir:     dir,
+		entries: map[string]*entry{".": {name: ".", mode: fs.ModeDir | 0o555}},
+		blobs:   &blobReader{ctx: ctx, dir: dir},
+	}
+}
+
+// add records a blob at name, creating its parent directories.
+func (f *FS) add(name, gitMode, oid string, size int64) {
+	mode := fs.FileMode(0o444)
+	switch gitMode {
+	case "120000":
+		mode = fs.ModeSymlink | 0o777
+	case "100755":
+		mode = 0o555
+	}
+	e := &entry{name: path.Base(name), mode: mode, oid: oid, size: size}
+	f.entries[name] = e
+
+	for child, dir := e, path.Dir(name); ; dir = path.Dir(dir) {
+		parent, ok := f.entries[dir]
+		if !ok {
+			parent = &entry{name: path.Base(dir), mode: fs.ModeDir | 0o555}
+			f.entries[dir] = parent
+		}
+		parent.children = append(parent.children, child)
+		if ok || dir == "." {
+			return
+		}
+		child = parent
+	}
+}
+
+// Close stops the git process reading blobs, if one was started.
+func (f *FS) Close() error {
+	return f.blobs.close()
+}
+
+// Open opens the named file, following symlinks.
+func (f *FS) Open(name string) (fs.File, error) {
+	e, err := f.lookup("open", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if e.mode.IsDir() {
+		return &dir{info: f.info(e), entries: f.dirEntries(e)}, nil
+	}
+	data, err := f.blobs.read(e.oid)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &file{info: f.info(e), Reader: bytes.NewReader(data)}, nil
+}
+
+// ReadDir reads the named directory, returning its entries sorted by name.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	e, err := f.lookup("readdir", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if !e.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return f.dirEntries(e), nil
+}
+
+// Stat returns the FileInfo of the named file, following symlinks.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	e, err := f.lookup("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return f.info(e), nil
+}
+
+// Lstat returns the FileInfo of the named file without following a final
+// symlink.
+func (f *FS) Lstat(name string) (fs.FileInfo, error) {
+	e, err := f.lookup("lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return f.info(e), nil
+}
+
+// ReadLink returns the target of the named symlink.
+func (f *FS) ReadLink(name string) (string, error) {
+	e, err := f.lookup("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	if e.mode.Type() != fs.ModeSymlink {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	target, err := f.blobs.read(e.oid)
+	if err != nil {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: err}
+	}
+	return string(target), nil
+}
+
+// lookup finds the entry for name, resolving symlinks in its directory part
+// and, if follow is set, in its final element.
+func (f *FS) lookup(op, name string, follow bool) (*entry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	var resolved string
+	pending := strings.Split(name, "/")
+	hops := 0
+	e := f.entries["."]
+	for len(pending) > 0 {
+		elem := pending[0]
+		pending = pending[1:]
+		switch elem {
+		case ".", "":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			e = f.entries[resolved]
+			continue
+		}
+
+		candidate := path.Join(resolved, elem)
+		next, ok := f.entries[candidate]
+		if !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		if next.mode.Type() != fs.ModeSymlink || (len(pending) == 0 && !follow) {
+			resolved, e = candidate, next
+			continue
+		}
+
+		hops++
+		if hops > maxLinkHops {
+			return nil, &fs.PathError{Op: op, Path: name, Err: errors.New("too many levels of symbolic links")}
+		}
+		target, err := f.blobs.read(next.oid)
+		if err != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		if path.IsAbs(string(target)) || strings.HasPrefix(path.Join(resolved, string(target)), "..") {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		pending = append(strings.Split(string(target), "/"), pending...)
+	}
+	return e, nil
+}
+
+func (f *FS) info(e *entry) fs.FileInfo {
+	return fileInfo{e: e}
+}
+
+func (f *FS) dirEntries(e *entry) []fs.DirEntry {
+	children := slices.Clone(e.children)
+	slices.SortFunc(children, func(a, b *entry) int { return strings.Compare(a.name, b.name) })
+	out := make([]fs.DirEntry, len(children))
+	for i, c := range children {
+		out[i] = fs.FileInfoToDirEntry(f.info(c))
+	}
+	return out
+}
+
+// fileInfo implements fs.FileInfo for an entry. Snapshots carry no
+// modification times.
+type fileInfo struct {
+	e *entry
+}
+
+func (i fileInfo) Name() string       { return i.e.name }
+func (i fileInfo) Size() int64        { return i.e.size }
+func (i fileInfo) Mode() fs.FileMode  { return i.e.mode }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.e.mode.IsDir() }
+func (i fileInfo) Sys() any           { return nil }
+
+// file is an open regular file.
+type file struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+// dir is an open directory.
+type dir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: errors.New("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
+
+// blobReader reads blobs through a long-running "git cat-file --batch".
+type blobReader struct {
+	ctx context.Context
+	dir string
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// read returns the contents of the blob oid.
+func (b *blobReader) read(oid string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cmd == nil {
+		if err := b.start(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := io.WriteString(b.stdin, oid+"\n"); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+
+	// <oid> SP <type> SP <size> LF <contents> LF
+	header, err := b.stdout.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("git cat-file: object %s: %s", oid, strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: unexpected header %q", header)
+	}
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(b.stdout, data); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	return data[:size], nil
+}
+
+func (b *blobReader) start() error {
+	cmd := exec.CommandContext(b.ctx, "git", "-C", b.dir, "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git cat-file: %w", err)
+	}
+	b.cmd, b.stdin, b.stdout = cmd, stdin, bufio.NewReader(stdout)
+	return nil
+}
+
+func (b *blobReader) close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cmd == nil {
+		return nil
+	}
+	_ = b.stdin.Close()
+	err := b.cmd.Wait()
+	b.cmd = nil
+	return err
+}
@@ -0,0 +1,180 @@
+package git_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/git"
+)
+
+// newRepo creates a git repository in a temporary directory with files
+// committed, and returns its path.
+func newRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q", "-b", "main")
+	writeFiles(t, dir, files)
+	commit(t, dir, "initial")
+	return dir
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func commit(t *testing.T, dir, msg string) {
+	t.Helper()
+
+	gitCmd(t, dir, "add", "-A")
+	gitCmd(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", msg)
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestTreeFS(t *testing.T) {
+	t.Parallel()
+
+	dir := newRepo(t, map[string]string{
+		"main.go":        "// CodeOwner: @old-team\npackage main\n",
+		"api/handler.go": "package api\n",
+		"api/.codeowner": "@api-team\n",
+	})
+	if err := os.Symlink("api", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, dir, "add link")
+
+	// Working tree changes must not be visible in the tree.
+	writeFiles(t, dir, map[string]string{
+		"main.go":   "// CodeOwner: @new-team\npackage main\n",
+		"untracked": "new\n",
+	})
+
+	fsys, err := git.TreeFS(context.Background(), dir, "HEAD")
+	if err != nil {
+		t.Fatalf("TreeFS error: %v", err)
+	}
+	t.Cleanup(func() { _ = fsys.Close() })
+
+	if err := fstest.TestFS(fsys, "main.go", "api/handler.go", "api/.codeowner"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.ReadFile(fsys, "link/handler.go"); err != nil {
+		t.Errorf("reading through a symlink: %v", err)
+	}
+
+	data, err := fs.ReadFile(fsys, "main.go")
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	if !strings.Contains(string(data), "@old-team") {
+		t.Errorf("main.go = %q, want committed content", data)
+	}
+	if _, err := fs.Stat(fsys, "untracked"); err == nil {
+		t.Error("untracked files should not be part of the tree")
+	}
+
+	target, err := fs.ReadLink(fsys, "link")
+	if err != nil {
+		t.Fatalf("ReadLink error: %v", err)
+	}
+	if target != "api" {
+		t.Errorf("ReadLink = %q, want %q", target, "api")
+	}
+	info, err := fs.Lstat(fsys, "link")
+	if err != nil {
+		t.Fatalf("Lstat error: %v", err)
+	}
+	if info.Mode().Type() != fs.ModeSymlink {
+		t.Errorf("Lstat mode = %v, want symlink", info.Mode())
+	}
+}
+
+func TestTreeFS_Revision(t *testing.T) {
+	t.Parallel()
+
+	dir := newRepo(t, map[string]string{"a.go": "// v1\n"})
+	gitCmd(t, dir, "tag", "v1")
+	writeFiles(t, dir, map[string]string{"a.go": "// v2\n", "b.go": "// v2\n"})
+	commit(t, dir, "second")
+
+	fsys, err := git.TreeFS(context.Background(), dir, "v1")
+	if err != nil {
+		t.Fatalf("TreeFS error: %v", err)
+	}
+	t.Cleanup(func() { _ = fsys.Close() })
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatalf("ReadDir error: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !slices.Equal(names, []string{"a.go"}) {
+		t.Errorf("entries = %v, want [a.go]", names)
+	}
+}
+
+func TestTreeFS_Subdirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := newRepo(t, map[string]string{
+		"root.go":     "package root\n",
+		"sub/file.go": "package sub\n",
+	})
+
+	fsys, err := git.TreeFS(context.Background(), filepath.Join(dir, "sub"), "HEAD")
+	if err != nil {
+		t.Fatalf("TreeFS error: %v", err)
+	}
+	t.Cleanup(func() { _ = fsys.Close() })
+
+	if err := fstest.TestFS(fsys, "file.go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat(fsys, "root.go"); err == nil {
+		t.Error("files outside the subdirectory should not be visible")
+	}
+}
+
+func TestTreeFS_InvalidRevision(t *testing.T) {
+	t.Parallel()
+
+	dir := newRepo(t, map[string]string{"a.go": "package a\n"})
+
+	_, err := git.TreeFS(context.Background(), dir, "does-not-exist")
+	if err == nil {
+		t.Fatal("expected error for unknown revision")
+	}
+	if !strings.Contains(err.Error(), "git ls-tree") {
+		t.Errorf("error should mention git ls-tree, got: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+// Package git reads trees, the index and changed files from a local git
+// repository by running the git command.
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Run executes git with args in dir and returns its standard output. Errors
+// include git's standard error output.
+func Run(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	return run(ctx, dir, nil, args...)
+}
+
+// run is Run with stdin connected to the given reader.
+func run(ctx context.Context, dir string, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// splitZ splits NUL-terminated output as produced by git's -z flag.
+func splitZ(out []byte) []string {
+	s := strings.TrimSuffix(string(out), "\x00")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\x00")
+}
+
+// ChangedFiles returns the files changed on HEAD since it diverged from base
+// (git diff base...HEAD), relative to dir.
+func ChangedFiles(ctx context.Context, dir, base string) ([]string, error) {
+	out, err := Run(ctx, dir, "diff", "--name-only", "-z", "--relative", base+"...HEAD", "--")
+	if err != nil {
+		return nil, err
+	}
+	return splitZ(out), nil
+}
+
+// AddedFiles returns the files staged for addition in the index, relative to
+// dir. Renamed files count as added under their new name.
+func AddedFiles(ctx context.Context, dir string) ([]string, error) {
+	out, err := Run(ctx, dir, "diff", "--cached", "--name-only", "-z", "--relative", "--no-renames", "--diff-filter=A", "--")
+	if err != nil {
+		return nil, err
+	}
+	return splitZ(out), nil
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IndexFS returns the files staged in the index as seen from dir, so that
+// content is read as it will be committed rather than from the working tree.
+// Paths are relative to dir. Submodules and unmerged entries are omitted.
+func IndexFS(ctx context.Context, dir string) (*FS, error) {
+	out, err := Run(ctx, dir, "ls-files", "-s", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	type staged struct{ name, mode, oid string }
+	var files []staged
+	for _, rec := range splitZ(out) {
+		// <mode> SP <object> SP <stage> TAB <path>
+		meta, name, ok := strings.Cut(rec, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 {
+			return nil, fmt.Errorf("git ls-files: unexpected output %q", rec)
+		}
+		if fields[2] != "0" || fields[0] == "160000" {
+			continue
+		}
+		files = append(files, staged{name: name, mode: fields[0], oid: fields[1]})
+	}
+
+	oids := make([]string, len(files))
+	for i, f := range files {
+		oids[i] = f.oid
+	}
+	sizes, err := blobSizes(ctx, dir, oids)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := newFS(ctx, dir)
+	for _, f := range files {
+		fsys.add(f.name, f.mode, f.oid, sizes[f.oid])
+	}
+	return fsys, nil
+}
+
+// blobSizes returns the size of each blob in oids, looked up with a single
+// "git cat-file --batch-check".
+func blobSizes(ctx context.Context, dir string, oids []string) (map[string]int64, error) {
+	sizes := make(map[string]int64, len(oids))
+	if len(oids) == 0 {
+		return sizes, nil
+	}
+
+	out, err := run(ctx, dir, strings.NewReader(strings.Join(oids, "\n")+"\n"), "cat-file", "--batch-check")
+	if err != nil {
+		return nil, err
+	}
+	for line := range strings.Lines(string(out)) {
+		// <oid> SP <type> SP <size> LF
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("git cat-file: %s", strings.TrimSpace(line))
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("git cat-file: unexpected output %q", line)
+		}
+		sizes[fields[0]] = size
+	}
+	return sizes, nil
+}
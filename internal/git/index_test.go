@@ -0,0 +1,111 @@
+package git_test
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/git"
+)
+
+func TestIndexFS(t *testing.T) {
+	t.Parallel()
+
+	dir := newRepo(t, map[string]string{
+		"main.go":        "// CodeOwner: @old-team\npackage main\n",
+		"api/handler.go": "package api\n",
+	})
+	writeFiles(t, dir, map[string]string{
+		"main.go":      "// CodeOwner: @staged-team\npackage main\n",
+		"api/new.go":   "package api\n",
+		"unstaged.txt": "not added\n",
+	})
+	gitCmd(t, dir, "add", "main.go", "api/new.go")
+	// Working tree changes after staging must not be visible.
+	writeFiles(t, dir, map[string]string{"main.go": "// CodeOwner: @unstaged-team\npackage main\n"})
+
+	fsys, err := git.IndexFS(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("IndexFS error: %v", err)
+	}
+	t.Cleanup(func() { _ = fsys.Close() })
+
+	if err := fstest.TestFS(fsys, "main.go", "api/handler.go", "api/new.go"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fs.ReadFile(fsys, "main.go")
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	want := "// CodeOwner: @staged-team\npackage main\n"
+	if string(data) != want {
+		t.Errorf("main.go = %q, want %q", data, want)
+	}
+	info, err := fs.Stat(fsys, "main.go")
+	if err != nil {
+		t.Fatalf("Stat error: %v", err)
+	}
+	if info.Size() != int64(len(want)) {
+		t.Errorf("size = %d, want %d", info.Size(), len(want))
+	}
+	if _, err := fs.Stat(fsys, "unstaged.txt"); err == nil {
+		t.Error("unstaged files should not be part of the index")
+	}
+}
+
+func TestIndexFS_Empty(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+
+	fsys, err := git.IndexFS(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("IndexFS error: %v", err)
+	}
+	t.Cleanup(func() { _ = fsys.Close() })
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatalf("ReadDir error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected an empty index, got %v", entries)
+	}
+}
+
+func TestAddedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := newRepo(t, map[string]string{
+		"keep.go":    "package a\n",
+		"old/mv.go":  "package a\n",
+		"sub/mod.go": "package sub\n",
+	})
+	writeFiles(t, dir, map[string]string{
+		"new.go":     "package a\n",
+		"sub/new.go": "package sub\n",
+		"sub/mod.go": "package sub // changed\n",
+	})
+	gitCmd(t, dir, "mv", "old/mv.go", "moved.go")
+	gitCmd(t, dir, "add", "-A")
+
+	got, err := git.AddedFiles(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("AddedFiles error: %v", err)
+	}
+	if want := []string{"moved.go", "new.go", "sub/new.go"}; !slices.Equal(got, want) {
+		t.Errorf("AddedFiles = %v, want %v", got, want)
+	}
+
+	got, err = git.AddedFiles(context.Background(), filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatalf("AddedFiles error: %v", err)
+	}
+	if want := []string{"new.go"}; !slices.Equal(got, want) {
+		t.Errorf("AddedFiles(sub) = %v, want %v", got, want)
+	}
+}
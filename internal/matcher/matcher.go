@@ -0,0 +1,160 @@
+// Package matcher evaluates CODEOWNERS rules the way GitHub does: patterns
+// follow gitignore syntax and the last matching rule decides a file's owners.
+package matcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Rule is a single CODEOWNERS line.
+type Rule struct {
+	Pattern string
+	Owners  []string
+	Line    int
+}
+
+// Ruleset is a parsed CODEOWNERS file, in file order.
+type Ruleset []Rule
+
+// Parse reads a CODEOWNERS file. Blank lines and comments are skipped, and
+// owners end at the first token starting with #.
+func Parse(r io.Reader) (Ruleset, error) {
+	var rules Ruleset
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		pattern, rest := splitPattern(text)
+		rule := Rule{Pattern: pattern, Line: line}
+		for _, tok := range strings.Fields(rest) {
+			if strings.HasPrefix(tok, "#") {
+				break
+			}
+			rule.Owners = append(rule.Owners, tok)
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return rules, fmt.Errorf("reading CODEOWNERS: %w", err)
+	}
+	return rules, nil
+}
+
+// ParseString is like Parse but reads from s.
+func ParseString(s string) Ruleset {
+	// Reading from a string cannot fail.
+	rules, _ := Parse(strings.NewReader(s))
+	return rules
+}
+
+// splitPattern splits a rule line at the first whitespace not escaped with
+// a backslash.
+func splitPattern(line string) (pattern, rest string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case ' ', '\t':
+			return line[:i], line[i:]
+		}
+	}
+	return line, ""
+}
+
+// Match returns the last rule matching the slash-separated file path, which
+// may start with "/". A matching rule without owners leaves the file
+// unowned.
+func (rs Ruleset) Match(name string) (Rule, bool) {
+	for i := len(rs) - 1; i >= 0; i-- {
+		if MatchPattern(rs[i].Pattern, name) {
+			return rs[i], true
+		}
+	}
+	return Rule{}, false
+}
+
+// Owners returns the effective owners of the file path, or nil if it has
+// none.
+func (rs Ruleset) Owners(name string) []string {
+	rule, _ := rs.Match(name)
+	return rule.Owners
+}
+
+// MatchPattern reports whether the CODEOWNERS pattern matches the file path.
+// A pattern matching a directory matches every file below it, except that,
+// as on GitHub, a final segment with a "*" wildcard ("docs/*") only matches
+// files directly inside. Patterns without a slash, other than a trailing
+// one, match at any depth, and a trailing slash only matches directories.
+func MatchPattern(pattern, name string) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.Trim(pattern, "/")
+	if p == "" {
+		// "/" is the repository root, which contains every file.
+		return pattern != ""
+	}
+	segs := strings.Split(p, "/")
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		segs = append([]string{"**"}, segs...)
+	}
+
+	last := segs[len(segs)-1]
+	filesOnly := last != "**" && hasWildcard(last)
+
+	parts := strings.Split(strings.Trim(name, "/"), "/")
+	for n := 1; n <= len(parts); n++ {
+		isFile := n == len(parts)
+		if (isFile && dirOnly) || (!isFile && filesOnly) {
+			continue
+		}
+		if matchSegments(segs, parts[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasWildcard reports whether the pattern segment contains a "*" that is not
+// escaped with a backslash.
+func hasWildcard(seg string) bool {
+	for i := 0; i < len(seg); i++ {
+		switch seg[i] {
+		case '\\':
+			i++
+		case '*':
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where "**"
+// matches any number of segments.
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
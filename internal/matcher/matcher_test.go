@@ -0,0 +1,110 @@
+package matcher_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+)
+
+func TestMatchPattern(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/", "main.go", true},
+		{"/", "src/deep/main.go", true},
+		{"*", "src/main.go", true},
+		{"*.js", "web/app.js", true},
+		{"*.js", "web/app.ts", false},
+		{"/main.go", "main.go", true},
+		{"/main.go", "/main.go", true},
+		{"/main.go", "src/main.go", false},
+		{"main.go", "src/main.go", true},
+		{"/src/", "src/main.go", true},
+		{"/src/", "src/deep/main.go", true},
+		{"/src/", "src", false},
+		{"/src/", "other/src/main.go", false},
+		{"src/", "other/src/main.go", true},
+		{"/docs/*", "docs/a.md", true},
+		{"/docs/*", "docs/sub/a.md", false},
+		{"docs/*", "other/docs/a.md", false},
+		{"/docs/**/a.md", "docs/x/y/a.md", true},
+		{"/docs/**/a.md", "docs/a.md", true},
+		{"**/logs", "deep/logs/x.log", true},
+		{"/apps/github", "apps/github/x.go", true},
+		{"CODEOWNERS", ".github/CODEOWNERS", true},
+		{"/file?.go", "file1.go", true},
+		{`/my\ file.go`, "my file.go", true},
+		{`/a\*b.go`, "a*b.go", true},
+		{`/a\*b.go`, "axb.go", false},
+		{`/docs/\*`, "docs/*/a.md", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.path, func(t *testing.T) {
+			t.Parallel()
+			if got := matcher.MatchPattern(tc.pattern, tc.path); got != tc.want {
+				t.Errorf("MatchPattern(%q, %q) = %v, want %v", tc.pattern, tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	input := "# Global owners\n" +
+		"\n" +
+		"* @everyone\n" +
+		"/src/ @backend @sre # inline comment\n" +
+		`/my\ file.go @docs` + "\n" +
+		"/vendor/\n"
+
+	rules, err := matcher.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := matcher.Ruleset{
+		{Pattern: "*", Owners: []string{"@everyone"}, Line: 3},
+		{Pattern: "/src/", Owners: []string{"@backend", "@sre"}, Line: 4},
+		{Pattern: `/my\ file.go`, Owners: []string{"@docs"}, Line: 5},
+		{Pattern: "/vendor/", Line: 6},
+	}
+	if !slices.EqualFunc(rules, want, func(a, b matcher.Rule) bool {
+		return a.Pattern == b.Pattern && slices.Equal(a.Owners, b.Owners) && a.Line == b.Line
+	}) {
+		t.Errorf("Parse:\ngot:  %v\nwant: %v", rules, want)
+	}
+}
+
+func TestRuleset_Owners(t *testing.T) {
+	t.Parallel()
+
+	rules := matcher.ParseString("/ @root\n" +
+		"/src/ @backend\n" +
+		"/src/web/index.html @frontend\n" +
+		"/vendor/\n")
+
+	testCases := []struct {
+		path string
+		want []string
+	}{
+		{"README.md", []string{"@root"}},
+		{"src/main.go", []string{"@backend"}},
+		{"src/web/index.html", []string{"@frontend"}},
+		{"/src/web/app.js", []string{"@backend"}},
+		{"vendor/lib.go", nil},
+	}
+
+	for _, tc := range testCases {
+		if got := rules.Owners(tc.path); !slices.Equal(got, tc.want) {
+			t.Errorf("Owners(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
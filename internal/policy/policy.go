@@ -0,0 +1,113 @@
+// Package policy checks scan results against ownership rules declared in the
+// configuration, such as "every file under services/ must be owned".
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+// Requirement is what a rule demands of the files it selects.
+type Requirement string
+
+// Requirements.
+const (
+	// RequireOwner demands an effective owner, from an annotation or a
+	// directory owner file.
+	RequireOwner Requirement = "owner"
+	// RequireAnnotation demands an annotation in the file itself; a
+	// directory owner is not enough.
+	RequireAnnotation Requirement = "annotation"
+)
+
+// Rule applies a requirement to the files matching any of its paths.
+type Rule struct {
+	// Name identifies the rule in violations. It defaults to the paths.
+	Name string `yaml:"name"`
+	// Paths are CODEOWNERS-style patterns selecting the files the rule
+	// applies to, e.g. "/services/" or "*.proto".
+	Paths []string `yaml:"paths"`
+	// Require is what the selected files must have.
+	Require Requirement `yaml:"require"`
+}
+
+// Validate reports whether the rule is complete and uses a known
+// requirement.
+func (r Rule) Validate() error {
+	if len(r.Paths) == 0 {
+		return errors.New("no paths")
+	}
+	for _, p := range r.Paths {
+		if strings.TrimSpace(p) == "" {
+			return errors.New("empty path")
+		}
+	}
+	switch r.Require {
+	case RequireOwner, RequireAnnotation:
+		return nil
+	case "":
+		return fmt.Errorf("missing require (%s or %s)", RequireOwner, RequireAnnotation)
+	default:
+		return fmt.Errorf("unknown require %q (want %s or %s)", r.Require, RequireOwner, RequireAnnotation)
+	}
+}
+
+// String returns the rule's name.
+func (r Rule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return strings.Join(r.Paths, " ")
+}
+
+// Violation is a file that does not meet a rule.
+type Violation struct {
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Evaluate checks every file against the rules that select it, given the
+// mappings found by the scanner, and returns the violations sorted by path.
+// Files are slash-separated paths relative to the scanned root.
+func Evaluate(rules []Rule, mappings []scanning.Mapping, files []string) []Violation {
+	owners := matcher.ParseString(formatter.CodeOwners(mappings))
+	annotated := make(map[string]bool, len(mappings))
+	for _, m := range mappings {
+		if !strings.HasSuffix(m.Path, "/") {
+			annotated[m.Path] = true
+		}
+	}
+
+	violations := []Violation{}
+	for _, f := range slices.Sorted(slices.Values(files)) {
+		for _, r := range rules {
+			if !selects(r, f) {
+				continue
+			}
+			switch {
+			case r.Require == RequireAnnotation && !annotated["/"+f]:
+				violations = append(violations, Violation{Path: "/" + f, Rule: r.String(), Message: "no annotation in the file"})
+			case r.Require == RequireOwner && len(owners.Owners(f)) == 0:
+				violations = append(violations, Violation{Path: "/" + f, Rule: r.String(), Message: "no owner"})
+			}
+		}
+	}
+	return violations
+}
+
+// selects reports whether any of the rule's paths matches the file.
+func selects(r Rule, file string) bool {
+	for _, p := range r.Paths {
+		if matcher.MatchPattern(p, file) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package policy_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/policy"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	mappings := []scanning.Mapping{
+		{Path: "/services/billing/", Owners: []string{"@billing"}},
+		{Path: "/services/billing/api.proto", Owners: []string{"@billing-api"}},
+		{Path: "/services/search/main.go", Owners: []string{"@search"}},
+	}
+	files := []string{
+		"services/search/main.go",
+		"services/search/index.go",
+		"services/billing/api.proto",
+		"services/billing/events.proto",
+		"services/billing/main.go",
+		"tools/gen.go",
+	}
+	rules := []policy.Rule{
+		{Name: "services are owned", Paths: []string{"/services/"}, Require: policy.RequireOwner},
+		{Paths: []string{"*.proto"}, Require: policy.RequireAnnotation},
+	}
+
+	got := policy.Evaluate(rules, mappings, files)
+	want := []policy.Violation{
+		{Path: "/services/billing/events.proto", Rule: "*.proto", Message: "no annotation in the file"},
+		{Path: "/services/search/index.go", Rule: "services are owned", Message: "no owner"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Evaluate:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestEvaluate_NoViolations(t *testing.T) {
+	t.Parallel()
+
+	mappings := []scanning.Mapping{{Path: "/", Owners: []string{"@everyone"}}}
+	rules := []policy.Rule{{Paths: []string{"/"}, Require: policy.RequireOwner}}
+
+	got := policy.Evaluate(rules, mappings, []string{"a.go", "dir/b.go"})
+	if got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil result, got %#v", got)
+	}
+}
+
+func TestRule_Validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		rule policy.Rule
+		want string
+	}{
+		{name: "valid", rule: policy.Rule{Paths: []string{"/a/"}, Require: policy.RequireOwner}},
+		{name: "no paths", rule: policy.Rule{Require: policy.RequireOwner}, want: "no paths"},
+		{name: "empty path", rule: policy.Rule{Paths: []string{" "}, Require: policy.RequireOwner}, want: "empty path"},
+		{name: "missing require", rule: policy.Rule{Paths: []string{"/a/"}}, want: "missing require"},
+		{name: "unknown require", rule: policy.Rule{Paths: []string{"/a/"}, Require: "team"}, want: `unknown require "team"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.rule.Validate()
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package rewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+	"go.yaml.in/yaml/v3"
+)
+
+// BackstageAnnotation is the annotation ExportBackstage sets to the owners
+// of a Backstage component.
+const BackstageAnnotation = "codeowner/owners"
+
+// ExportBackstage returns the edits that set BackstageAnnotation on every
+// Component entity of the catalog-info.yaml files in fsys, as selected by
+// opts, to the owners that owners reports for the file, space-separated.
+// Files without owners are left alone. The annotation is added or replaced
+// line by line, leaving the rest of the file untouched.
+func ExportBackstage(ctx context.Context, fsys fs.FS, opts scanning.Options, owners func(path string) []string) ([]Edit, error) {
+	files, err := scanning.ListFiles(ctx, fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []Edit
+	for _, name := range files {
+		if path.Base(name) != scanning.BackstageFile {
+			continue
+		}
+		o := owners(name)
+		if len(o) == 0 {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		after, err := setAnnotation(data, BackstageAnnotation, strings.Join(o, " "))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if !bytes.Equal(after, data) {
+			edits = append(edits, Edit{Path: name, Before: data, After: after})
+		}
+	}
+	return edits, nil
+}
+
+// lineEdit replaces line from column col on by text, or, if insert is set,
+// inserts text as a new line before it. Lines and columns start at 0.
+type lineEdit struct {
+	line, col int
+	insert    bool
+	text      string
+}
+
+// setAnnotation sets the annotation key to value in the metadata of every
+// Component entity of the YAML stream data.
+func setAnnotation(data []byte, key, value string) ([]byte, error) {
+	var edits []lineEdit
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		entity := doc.Content[0]
+		if kind := lookup(entity, "kind"); kind == nil || kind.Value != "Component" {
+			continue
+		}
+		e, ok, err := annotationEdit(entity, key, value)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			edits = append(edits, e)
+		}
+	}
+	if len(edits) == 0 {
+		return data, nil
+	}
+
+	lines := slices.Collect(strings.Lines(string(data)))
+	// Edit from the bottom up so that earlier line numbers stay valid.
+	slices.Reverse(edits)
+	for _, e := range edits {
+		if e.insert {
+			lines = slices.Insert(lines, e.line, e.text)
+			continue
+		}
+		l := lines[e.line]
+		ending := l[len(strings.TrimRight(l, "\r\n")):]
+		lines[e.line] = l[:e.col] + e.text + ending
+	}
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// annotationEdit returns the edit setting the annotation key to value in the
+// metadata of entity, and false if it is set already.
+func annotationEdit(entity *yaml.Node, key, value string) (lineEdit, bool, error) {
+	metaKey, meta := lookupPair(entity, "metadata")
+	if !isBlockMapping(meta) {
+		return lineEdit{}, false, errors.New("metadata is not a block mapping")
+	}
+	quoted := key + ": " + strconv.Quote(value)
+
+	_, annotations := lookupPair(meta, "annotations")
+	if annotations == nil {
+		// Add the annotations before the first metadata field, indented
+		// like the metadata below its parent.
+		first := meta.Content[0]
+		indent := strings.Repeat(" ", first.Column-1)
+		step := strings.Repeat(" ", max(first.Column-metaKey.Column, 2))
+		return lineEdit{line: first.Line - 1, insert: true, text: indent + "annotations:\n" + indent + step + quoted + "\n"}, true, nil
+	}
+	if !isBlockMapping(annotations) {
+		return lineEdit{}, false, errors.New("metadata.annotations is not a block mapping")
+	}
+
+	k, v := lookupPair(annotations, key)
+	if k == nil {
+		first := annotations.Content[0]
+		return lineEdit{line: first.Line - 1, insert: true, text: strings.Repeat(" ", first.Column-1) + quoted + "\n"}, true, nil
+	}
+	if v.Kind == yaml.ScalarNode && v.Value == value {
+		return lineEdit{}, false, nil
+	}
+	if v.Kind != yaml.ScalarNode || v.Line != k.Line || strings.Contains(v.Value, "\n") {
+		return lineEdit{}, false, fmt.Errorf("annotation %s is not a single-line string", key)
+	}
+	return lineEdit{line: k.Line - 1, col: k.Column - 1, text: quoted}, true, nil
+}
+
+// isBlockMapping reports whether n is a non-empty mapping in block style.
+func isBlockMapping(n *yaml.Node) bool {
+	return n != nil && n.Kind == yaml.MappingNode && n.Style&yaml.FlowStyle == 0 && len(n.Content) > 0
+}
+
+// lookup returns the value of key in the mapping n, or nil.
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	_, v := lookupPair(n, key)
+	return v
+}
+
+// lookupPair returns the key and value nodes of key in the mapping n, or
+// nils.
+func lookupPair(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+package rewrite_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/rewrite"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+func TestExportBackstage(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "adds annotations",
+			content: "apiVersion: backstage.io/v1alpha1\nkind: Component\nmetadata:\n  name: web # the storefront\nspec:\n  owner: web-team\n",
+			want:    "apiVersion: backstage.io/v1alpha1\nkind: Component\nmetadata:\n  annotations:\n    codeowner/owners: \"@web @sre\"\n  name: web # the storefront\nspec:\n  owner: web-team\n",
+		},
+		{
+			name:    "keeps indentation",
+			content: "kind: Component\nmetadata:\n    name: web\n    annotations:\n        github.com/project-slug: org/web\n",
+			want:    "kind: Component\nmetadata:\n    name: web\n    annotations:\n        codeowner/owners: \"@web @sre\"\n        github.com/project-slug: org/web\n",
+		},
+		{
+			name:    "replaces the annotation",
+			content: "kind: Component\r\nmetadata:\r\n  annotations:\r\n    codeowner/owners: '@old'\r\n",
+			want:    "kind: Component\r\nmetadata:\r\n  annotations:\r\n    codeowner/owners: \"@web @sre\"\r\n",
+		},
+		{
+			name:    "up to date",
+			content: "kind: Component\nmetadata:\n  annotations:\n    codeowner/owners: '@web @sre'\n",
+		},
+		{
+			name:    "only components",
+			content: "kind: System\nmetadata:\n  name: shop\n---\nkind: Component\nmetadata:\n  name: web\n",
+			want:    "kind: System\nmetadata:\n  name: shop\n---\nkind: Component\nmetadata:\n  annotations:\n    codeowner/owners: \"@web @sre\"\n  name: web\n",
+		},
+		{
+			name:    "flow metadata",
+			content: "kind: Component\nmetadata: {name: web}\n",
+			wantErr: "web/catalog-info.yaml: metadata is not a block mapping",
+		},
+	}
+
+	owners := func(path string) []string {
+		if strings.HasPrefix(path, "web/") {
+			return []string{"@web", "@sre"}
+		}
+		return nil
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fsys := fstest.MapFS{
+				"web/catalog-info.yaml":   {Data: []byte(tc.content)},
+				"other/catalog-info.yaml": {Data: []byte("kind: Component\nmetadata:\n  name: other\n")},
+			}
+			edits, err := rewrite.ExportBackstage(context.Background(), fsys, scanning.Options{Prefix: scanning.DefaultPrefix}, owners)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("got error %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.want == "" {
+				if len(edits) != 0 {
+					t.Errorf("expected no edits, got %q", edits[0].After)
+				}
+				return
+			}
+			if len(edits) != 1 || edits[0].Path != "web/catalog-info.yaml" {
+				t.Fatalf("expected one edit of web/catalog-info.yaml, got %v", edits)
+			}
+			if got := string(edits[0].After); got != tc.want {
+				t.Errorf("content:\ngot:  %q\nwant: %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package rewrite
+
+import (
+	"path"
+	"strings"
+)
+
+// commentStyle is how a line comment is written in a language.
+type commentStyle struct {
+	open, close string
+}
+
+var (
+	slashComment = commentStyle{open: "//"}
+	hashComment  = commentStyle{open: "#"}
+	dashComment  = commentStyle{open: "--"}
+	semiComment  = commentStyle{open: ";"}
+	pctComment   = commentStyle{open: "%"}
+	htmlComment  = commentStyle{open: "<!--", close: "-->"}
+	cComment     = commentStyle{open: "/*", close: "*/"}
+	mlComment    = commentStyle{open: "(*", close: "*)"}
+)
+
+// commentStyles maps file extensions to their comment syntax.
+var commentStyles = map[string]commentStyle{
+	".c": slashComment, ".h": slashComment, ".cc": slashComment, ".cpp": slashComment, ".hpp": slashComment,
+	".cs": slashComment, ".dart": slashComment, ".go": slashComment, ".gradle": slashComment,
+	".groovy": slashComment, ".java": slashComment, ".js": slashComment, ".jsx": slashComment,
+	".cjs": slashComment, ".mjs": slashComment, ".kt": slashComment, ".kts": slashComment,
+	".less": slashComment, ".php": slashComment, ".proto": slashComment, ".rs": slashComment,
+	".scala": slashComment, ".scss": slashComment, ".swift": slashComment, ".ts": slashComment,
+	".tsx": slashComment, ".zig": slashComment,
+
+	".bash": hashComment, ".cfg": hashComment, ".cmake": hashComment, ".conf": hashComment,
+	".ex": hashComment, ".exs": hashComment, ".jl": hashComment, ".mk": hashComment,
+	".nix": hashComment, ".pl": hashComment, ".ps1": hashComment, ".py": hashComment,
+	".r": hashComment, ".rake": hashComment, ".rb": hashComment, ".sh": hashComment,
+	".tf": hashComment, ".toml": hashComment, ".yaml": hashComment, ".yml": hashComment,
+	".zsh": hashComment,
+
+	".elm": dashComment, ".hs": dashComment, ".lua": dashComment, ".sql": dashComment,
+
+	".clj": semiComment, ".el": semiComment, ".ini": semiComment, ".lisp": semiComment, ".scm": semiComment,
+
+	".erl": pctComment, ".tex": pctComment,
+
+	".htm": htmlComment, ".html": htmlComment, ".markdown": htmlComment, ".md": htmlComment,
+	".svg": htmlComment, ".vue": htmlComment, ".xml": htmlComment,
+
+	".css": cComment,
+
+	".ml": mlComment, ".mli": mlComment,
+}
+
+// commentFiles maps well-known file names without a telling extension to
+// their comment syntax.
+var commentFiles = map[string]commentStyle{
+	"Dockerfile": hashComment, "Makefile": hashComment, "Gemfile": hashComment, "Rakefile": hashComment,
+	"Brewfile": hashComment, "Procfile": hashComment, "CODEOWNERS": hashComment,
+	".gitignore": hashComment, ".gitattributes": hashComment, ".dockerignore": hashComment,
+	".editorconfig": hashComment, ".env": hashComment,
+}
+
+// commentFor returns the comment syntax of the named file.
+func commentFor(name string) (commentStyle, bool) {
+	base := path.Base(name)
+	if c, ok := commentFiles[base]; ok {
+		return c, true
+	}
+	c, ok := commentStyles[strings.ToLower(path.Ext(base))]
+	return c, ok
+}
+
+// line returns text as a comment line ending in eol.
+func (c commentStyle) line(text, eol string) string {
+	if c.close == "" {
+		return c.open + " " + text + eol
+	}
+	return c.open + " " + text + " " + c.close + eol
+}
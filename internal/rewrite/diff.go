@@ -0,0 +1,182 @@
+package rewrite
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// op is one step of a line diff: an unchanged, deleted or inserted line.
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// WriteDiff writes the edits to w as a unified diff, as produced by
+// "diff -u" or "git diff".
+func WriteDiff(w io.Writer, edits []Edit) error {
+	for _, e := range edits {
+		if _, err := io.WriteString(w, unified(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unified returns the unified diff of one edit.
+func unified(e Edit) string {
+	ops := diffLines(splitLines(string(e.Before)), splitLines(string(e.After)))
+
+	var b strings.Builder
+	if e.Before == nil {
+		fmt.Fprintf(&b, "--- /dev/null\n+++ b/%s\n", e.Path)
+	} else {
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", e.Path, e.Path)
+	}
+	for start := 0; start < len(ops); {
+		// Find the next change and the end of its hunk: the first run of
+		// more than twice the context of unchanged lines after it.
+		first := slices.IndexFunc(ops[start:], func(o op) bool { return o.kind != ' ' })
+		if first < 0 {
+			break
+		}
+		first += start
+		lo := max(first-contextLines, start)
+		hi, same := first, 0
+		for ; hi < len(ops) && same <= 2*contextLines; hi++ {
+			if ops[hi].kind == ' ' {
+				same++
+			} else {
+				same = 0
+			}
+		}
+		hi -= max(same-contextLines, 0)
+		writeHunk(&b, ops, lo, hi)
+		start = hi
+	}
+	return b.String()
+}
+
+// writeHunk writes ops[lo:hi] as one hunk.
+func writeHunk(b *strings.Builder, ops []op, lo, hi int) {
+	// Line numbers are 1-based positions in the old and new files.
+	oldStart, newStart := 1, 1
+	for _, o := range ops[:lo] {
+		if o.kind != '+' {
+			oldStart++
+		}
+		if o.kind != '-' {
+			newStart++
+		}
+	}
+	oldLen, newLen := 0, 0
+	for _, o := range ops[lo:hi] {
+		if o.kind != '+' {
+			oldLen++
+		}
+		if o.kind != '-' {
+			newLen++
+		}
+	}
+
+	// An empty range names the line before it, as in "@@ -0,0 +1,2 @@".
+	if oldLen == 0 {
+		oldStart--
+	}
+	if newLen == 0 {
+		newStart--
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldLen, newStart, newLen)
+	for _, o := range ops[lo:hi] {
+		b.WriteByte(o.kind)
+		b.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines splits s after each newline.
+func splitLines(s string) []string {
+	return slices.Collect(strings.Lines(s))
+}
+
+// diffLines computes a shortest edit script from a to b with Myers'
+// algorithm.
+func diffLines(a, b []string) []op {
+	trace, offset := searchPaths(a, b)
+	return backtrack(a, b, trace, offset)
+}
+
+// searchPaths runs the forward search of Myers' algorithm, returning the
+// furthest x reached on each diagonal k, at index offset+k, before each
+// edit distance d, until one path reaches the end of both a and b.
+func searchPaths(a, b []string) (trace [][]int, offset int) {
+	n, m := len(a), len(b)
+	offset = n + m + 1
+	v := make([]int, 2*offset+1)
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			x := v[offset+k-1] + 1
+			if fromAbove(v, offset, k, d) {
+				x = v[offset+k+1]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return trace, offset
+			}
+		}
+	}
+	return trace, offset
+}
+
+// fromAbove reports whether the path on diagonal k at distance d continues
+// the one on diagonal k+1, by an insertion, rather than the one on k-1, by
+// a deletion.
+func fromAbove(v []int, offset, k, d int) bool {
+	return k == -d || (k != d && v[offset+k-1] < v[offset+k+1])
+}
+
+// backtrack walks back from the end of a and b through the frontiers
+// recorded by searchPaths, returning the edit script in order.
+func backtrack(a, b []string, trace [][]int, offset int) []op {
+	var ops []op
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if fromAbove(v, offset, k, d) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{' ', a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, op{'+', b[y]})
+		} else {
+			x--
+			ops = append(ops, op{'-', a[x]})
+		}
+	}
+	slices.Reverse(ops)
+	return ops
+}
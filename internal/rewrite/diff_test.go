@@ -0,0 +1,64 @@
+package rewrite_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/rewrite"
+)
+
+func TestWriteDiff(t *testing.T) {
+	t.Parallel()
+
+	var before, after []string
+	for i := 1; i <= 20; i++ {
+		line := "line " + string(rune('a'+i-1)) + "\n"
+		before = append(before, line)
+		switch i {
+		case 2:
+			after = append(after, "changed b\n")
+		case 15:
+			after = append(after, "inserted\n", line)
+		default:
+			after = append(after, line)
+		}
+	}
+
+	testCases := []struct {
+		name string
+		edit rewrite.Edit
+		want string
+	}{
+		{
+			name: "two hunks",
+			edit: rewrite.Edit{Path: "a.txt", Before: []byte(strings.Join(before, "")), After: []byte(strings.Join(after, ""))},
+			want: "--- a/a.txt\n+++ b/a.txt\n" +
+				"@@ -1,5 +1,5 @@\n line a\n-line b\n+changed b\n line c\n line d\n line e\n" +
+				"@@ -12,6 +12,7 @@\n line l\n line m\n line n\n+inserted\n line o\n line p\n line q\n",
+		},
+		{
+			name: "new file",
+			edit: rewrite.Edit{Path: "api/.codeowner", After: []byte("@api\n")},
+			want: "--- /dev/null\n+++ b/api/.codeowner\n@@ -0,0 +1,1 @@\n+@api\n",
+		},
+		{
+			name: "no newline at end of file",
+			edit: rewrite.Edit{Path: "b.py", Before: []byte("# CodeOwner: @old"), After: []byte("# CodeOwner: @new")},
+			want: "--- a/b.py\n+++ b/b.py\n" +
+				"@@ -1,1 +1,1 @@\n-# CodeOwner: @old\n\\ No newline at end of file\n+# CodeOwner: @new\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var b strings.Builder
+			if err := rewrite.WriteDiff(&b, []rewrite.Edit{tc.edit}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b.String() != tc.want {
+				t.Errorf("WriteDiff:\ngot:\n%s\nwant:\n%s", b.String(), tc.want)
+			}
+		})
+	}
+}
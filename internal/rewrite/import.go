@@ -0,0 +1,264 @@
+package rewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/formatter"
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+// codingCookie matches a Python source encoding declaration, which must stay
+// on the first or second line of a file.
+var codingCookie = regexp.MustCompile(`^[ \t\f]*#.*?coding[:=]`)
+
+// importPreambles lists first lines an annotation must not be inserted
+// before.
+var importPreambles = []string{"#!", "<?php", "<?xml", "<!DOCTYPE", "<!doctype"}
+
+// Skipped is a CODEOWNERS rule that could not be turned into an annotation
+// or directory owner file.
+type Skipped struct {
+	Line    int
+	Pattern string
+	Reason  string
+}
+
+// ImportResult is the outcome of Import.
+type ImportResult struct {
+	// Edits create directory owner files and annotate files. New files have
+	// a nil Before.
+	Edits []Edit
+	// Skipped lists the rules that were not translated, in file order.
+	Skipped []Skipped
+	// Changed lists the files whose owners under the CODEOWNERS file the
+	// edits produce differ from their owners under the imported rules.
+	Changed []string
+}
+
+// target is where a translated rule is written: a directory owner file for
+// dir, or an annotation in the file.
+type target struct {
+	rule matcher.Rule
+	path string
+	dir  bool
+}
+
+// Import translates a CODEOWNERS file into directory owner files, for rules
+// naming a directory, and annotations, for rules naming a single file, in
+// fsys. Annotations are written with the comment syntax of each file's
+// extension. Rules that cannot be translated, such as wildcard patterns, are
+// reported, and the result is checked by comparing the owners of every file
+// under both CODEOWNERS files.
+func Import(ctx context.Context, fsys fs.FS, rules matcher.Ruleset, opts scanning.Options) (ImportResult, error) {
+	var res ImportResult
+	targets := make(map[string]target)
+	var order []string
+	for _, r := range rules {
+		t, reason := translate(fsys, r)
+		if reason != "" {
+			res.Skipped = append(res.Skipped, Skipped{Line: r.Line, Pattern: r.Pattern, Reason: reason})
+			continue
+		}
+		key := t.path
+		if t.dir {
+			key += "/"
+		}
+		if prev, ok := targets[key]; ok {
+			res.Skipped = append(res.Skipped, Skipped{Line: prev.rule.Line, Pattern: prev.rule.Pattern, Reason: fmt.Sprintf("overridden by line %d", r.Line)})
+		} else {
+			order = append(order, key)
+		}
+		targets[key] = t
+	}
+
+	var planned []scanning.Mapping
+	for _, key := range order {
+		t := targets[key]
+		edit, reason, err := plan(fsys, t, opts)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		if reason != "" {
+			res.Skipped = append(res.Skipped, Skipped{Line: t.rule.Line, Pattern: t.rule.Pattern, Reason: reason})
+			continue
+		}
+		if edit != nil {
+			res.Edits = append(res.Edits, *edit)
+			planned = append(planned, t.mapping())
+		}
+	}
+	slices.SortFunc(res.Skipped, func(a, b Skipped) int { return a.Line - b.Line })
+	slices.SortFunc(res.Edits, func(a, b Edit) int { return strings.Compare(a.Path, b.Path) })
+
+	changed, err := compareOwners(ctx, fsys, rules, planned, opts)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	res.Changed = changed
+	return res, nil
+}
+
+// translate returns where the rule can be written, or why it cannot.
+func translate(fsys fs.FS, r matcher.Rule) (target, string) {
+	if r.Pattern == "CODEOWNERS" {
+		return target{}, "protects the CODEOWNERS file itself, use --protect instead"
+	}
+	if len(r.Owners) == 0 {
+		return target{}, "rules without owners cannot be expressed as annotations"
+	}
+	for _, o := range r.Owners {
+		if err := scanning.ValidateOwner(o); err != nil {
+			return target{}, fmt.Sprintf("owner %q is not a @handle", o)
+		}
+	}
+
+	p := r.Pattern
+	switch p {
+	case "*", "**", "/", "/**":
+		return target{rule: r, path: ".", dir: true}, ""
+	}
+	anchored := strings.HasPrefix(p, "/") || strings.Contains(strings.TrimSuffix(p, "/"), "/")
+	p = strings.TrimSuffix(p, "/**")
+	if strings.ContainsAny(p, "*?[]\\!") {
+		return target{}, "wildcard patterns cannot be expressed as annotations"
+	}
+	if !anchored {
+		return target{}, "pattern matches at any depth, anchor it with a leading /"
+	}
+
+	name := strings.Trim(p, "/")
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return target{}, "no such file or directory"
+	}
+	if info.IsDir() {
+		return target{rule: r, path: name, dir: true}, ""
+	}
+	if strings.HasSuffix(p, "/") {
+		return target{}, "pattern only matches directories, but this is a file"
+	}
+	return target{rule: r, path: name}, ""
+}
+
+// mapping returns the scanner mapping the target produces once written.
+func (t target) mapping() scanning.Mapping {
+	switch {
+	case t.dir && t.path == ".":
+		return scanning.Mapping{Path: "/", Owners: t.rule.Owners}
+	case t.dir:
+		return scanning.Mapping{Path: "/" + t.path + "/", Owners: t.rule.Owners}
+	default:
+		return scanning.Mapping{Path: "/" + t.path, Owners: t.rule.Owners}
+	}
+}
+
+// plan returns the edit writing t, nil if the ownership is already in
+// place, or why it cannot be written.
+func plan(fsys fs.FS, t target, opts scanning.Options) (*Edit, string, error) {
+	if t.dir {
+		name := path.Join(t.path, opts.DirOwnerFile)
+		existing, err := scanning.ParseCodeOwnerFileFS(fsys, name)
+		switch {
+		case err == nil && slices.Equal(existing, t.rule.Owners):
+			return nil, "", nil
+		case err == nil:
+			return nil, fmt.Sprintf("%s already exists with owners %s", name, strings.Join(existing, " ")), nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, "", err
+		}
+		return &Edit{Path: name, After: []byte(strings.Join(t.rule.Owners, " ") + "\n")}, "", nil
+	}
+
+	existing, err := scanning.ParseFileFS(fsys, t.path, opts.Prefix)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) > 0 {
+		if slices.Equal(existing, t.rule.Owners) {
+			return nil, "", nil
+		}
+		return nil, fmt.Sprintf("already annotated with %s", strings.Join(existing, " ")), nil
+	}
+	style, ok := commentFor(t.path)
+	if !ok {
+		return nil, fmt.Sprintf("no known comment syntax for %s files", path.Base(t.path)), nil
+	}
+	data, err := fs.ReadFile(fsys, t.path)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxFileSize || bytes.IndexByte(data[:min(len(data), 512)], 0) >= 0 {
+		return nil, "binary or too large to annotate", nil
+	}
+	annotation := opts.Prefix + " " + strings.Join(t.rule.Owners, " ")
+	return &Edit{Path: t.path, Before: data, After: insertAnnotation(data, style, annotation)}, "", nil
+}
+
+// insertAnnotation adds text as a comment at the top of data, after any
+// shebang, language preamble or encoding declaration, followed by a blank
+// line to keep it apart from doc comments.
+func insertAnnotation(data []byte, style commentStyle, text string) []byte {
+	lines := slices.Collect(strings.Lines(string(data)))
+	eol := "\n"
+	if len(lines) > 0 && strings.HasSuffix(lines[0], "\r\n") {
+		eol = "\r\n"
+	}
+
+	at := 0
+	if at < len(lines) && hasPreamble(lines[at]) {
+		at++
+	}
+	if at < len(lines) && at < 2 && codingCookie.MatchString(lines[at]) {
+		at++
+	}
+	if at > 0 && !strings.HasSuffix(lines[at-1], "\n") {
+		lines[at-1] += eol
+	}
+
+	insert := []string{style.line(text, eol)}
+	if at < len(lines) && strings.TrimSpace(lines[at]) != "" {
+		insert = append(insert, eol)
+	}
+	return []byte(strings.Join(slices.Insert(lines, at, insert...), ""))
+}
+
+func hasPreamble(line string) bool {
+	for _, p := range importPreambles {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareOwners returns the files whose owners under rules differ from
+// those under the CODEOWNERS file generated from the existing annotations
+// plus planned.
+func compareOwners(ctx context.Context, fsys fs.FS, rules matcher.Ruleset, planned []scanning.Mapping, opts scanning.Options) ([]string, error) {
+	existing, err := scanning.ScanFS(ctx, fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+	generated := matcher.ParseString(formatter.CodeOwners(append(existing, planned...)))
+
+	files, err := scanning.ListFiles(ctx, fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, f := range files {
+		if !slices.Equal(rules.Owners(f), generated.Owners(f)) {
+			changed = append(changed, "/"+f)
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,112 @@
+package rewrite_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/rewrite"
+)
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"api/handler.go":    {Data: []byte("package api\n")},
+		"api/billing.go":    {Data: []byte("// Package api serves the API.\npackage api\n")},
+		"web/index.html":    {Data: []byte("<!DOCTYPE html>\r\n<html></html>\r\n")},
+		"scripts/deploy.py": {Data: []byte("#!/usr/bin/env python3\n# -*- coding: utf-8 -*-\nimport os\n")},
+		"scripts/run.sh":    {Data: []byte("#!/bin/sh")},
+		"data/config.json":  {Data: []byte("{}\n")},
+		"docs/guide.md":     {Data: []byte("<!-- CodeOwner: @writers -->\n# Guide\n")},
+		"docs/other.md":     {Data: []byte("# Other\n")},
+		"docs/.codeowner":   {Data: []byte("@docs\n")},
+		"README.md":         {Data: []byte("# Readme\n")},
+	}
+	rules := matcher.ParseString(`# legacy CODEOWNERS
+CODEOWNERS @admin
+*                    @everyone
+/api/                @backend
+/api/billing.go      @payments
+/web/index.html      @frontend
+/scripts/deploy.py   @sre
+/scripts/run.sh      @sre
+/data/config.json    @data
+/docs/**             @docs
+/docs/guide.md       @writers
+*.md                 @writers
+vendor/              @nobody
+/missing.go          @ghost
+/api/handler.go      dev@example.com
+`)
+
+	res, err := rewrite.Import(context.Background(), fsys, rules, defaultOpts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		".codeowner":        "@everyone\n",
+		"api/.codeowner":    "@backend\n",
+		"api/billing.go":    "// CodeOwner: @payments\n\n// Package api serves the API.\npackage api\n",
+		"web/index.html":    "<!DOCTYPE html>\r\n<!-- CodeOwner: @frontend -->\r\n\r\n<html></html>\r\n",
+		"scripts/deploy.py": "#!/usr/bin/env python3\n# -*- coding: utf-8 -*-\n# CodeOwner: @sre\n\nimport os\n",
+		"scripts/run.sh":    "#!/bin/sh\n# CodeOwner: @sre\n",
+	}
+	var paths []string
+	for _, e := range res.Edits {
+		paths = append(paths, e.Path)
+		if got := string(e.After); got != want[e.Path] {
+			t.Errorf("%s:\ngot:  %q\nwant: %q", e.Path, got, want[e.Path])
+		}
+		if isNew := e.Before == nil; isNew != (e.Path == ".codeowner" || e.Path == "api/.codeowner") {
+			t.Errorf("%s: unexpected Before %q", e.Path, e.Before)
+		}
+	}
+	if len(paths) != len(want) {
+		t.Errorf("edited %v, want %d files", paths, len(want))
+	}
+
+	wantSkipped := []rewrite.Skipped{
+		{Line: 2, Pattern: "CODEOWNERS", Reason: "protects the CODEOWNERS file itself, use --protect instead"},
+		{Line: 9, Pattern: "/data/config.json", Reason: "no known comment syntax for config.json files"},
+		{Line: 12, Pattern: "*.md", Reason: "wildcard patterns cannot be expressed as annotations"},
+		{Line: 13, Pattern: "vendor/", Reason: "pattern matches at any depth, anchor it with a leading /"},
+		{Line: 14, Pattern: "/missing.go", Reason: "no such file or directory"},
+		{Line: 15, Pattern: "/api/handler.go", Reason: `owner "dev@example.com" is not a @handle`},
+	}
+	if !slices.Equal(res.Skipped, wantSkipped) {
+		t.Errorf("Skipped:\ngot:  %v\nwant: %v", res.Skipped, wantSkipped)
+	}
+
+	// Files whose owner came from a skipped rule end up with a different
+	// owner after the import.
+	wantChanged := []string{"/README.md", "/api/handler.go", "/data/config.json", "/docs/other.md"}
+	if !slices.Equal(res.Changed, wantChanged) {
+		t.Errorf("Changed = %v, want %v", res.Changed, wantChanged)
+	}
+}
+
+func TestImport_Overridden(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{"a.go": {Data: []byte("package a\n")}}
+	rules := matcher.ParseString("/a.go @first\n/a.go @second\n")
+
+	res, err := rewrite.Import(context.Background(), fsys, rules, defaultOpts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Edits) != 1 || string(res.Edits[0].After) != "// CodeOwner: @second\n\npackage a\n" {
+		t.Errorf("unexpected edits: %v", res.Edits)
+	}
+	want := []rewrite.Skipped{{Line: 1, Pattern: "/a.go", Reason: "overridden by line 2"}}
+	if !slices.Equal(res.Skipped, want) {
+		t.Errorf("Skipped = %v, want %v", res.Skipped, want)
+	}
+	if len(res.Changed) != 0 {
+		t.Errorf("Changed = %v, want none", res.Changed)
+	}
+}
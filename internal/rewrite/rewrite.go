@@ -0,0 +1,194 @@
+// Package rewrite edits ownership annotations and directory owner files in
+// place, changing only the owner handles and leaving comment syntax and
+// whitespace untouched.
+package rewrite
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+// maxFileSize matches the scanner's limit: larger files carry no
+// annotations the scanner would read, so they are never edited.
+const maxFileSize = 1 << 20
+
+// Edit is the new content of one file.
+type Edit struct {
+	// Path is the slash-separated path relative to the root.
+	Path string
+	// Before is the current content, or nil if the file is created.
+	Before []byte
+	After  []byte
+}
+
+// RenameOwner returns the edits that replace the owner handle from by to in
+// every annotation and directory owner file in fsys, as selected by opts.
+// Handles are replaced only where they are whole tokens, so renaming @team
+// leaves @team-web alone, and not in the rationale after an annotation's
+// "--".
+func RenameOwner(ctx context.Context, fsys fs.FS, opts scanning.Options, from, to string) ([]Edit, error) {
+	return edit(ctx, fsys, opts, func(line string, dirOwner bool) string {
+		if dirOwner {
+			return replaceToken(line, from, to, "")
+		}
+		start := annotationStart(line, opts.Prefixes())
+		if start < 0 {
+			return line
+		}
+		return line[:start] + replaceToken(line[start:], from, to, "--")
+	})
+}
+
+// MigratePrefix returns the edits that replace the annotation prefix from by
+// to, in annotations and directory directives, in every file in fsys, as
+// selected by opts, leaving the owners and the surrounding comment
+// untouched. Only annotations naming an owner are changed, so prose that
+// happens to contain the prefix is left alone.
+func MigratePrefix(ctx context.Context, fsys fs.FS, opts scanning.Options, from, to string) ([]Edit, error) {
+	return edit(ctx, fsys, opts, func(line string, dirOwner bool) string {
+		if dirOwner {
+			return line
+		}
+		for _, p := range [][2]string{{scanning.DirDirective(from), scanning.DirDirective(to)}, {from, to}} {
+			start := scanning.AnnotationStart(line, p[0])
+			if start >= 0 && namesOwner(line[start:]) {
+				return line[:start-len(p[0])] + p[1] + line[start:]
+			}
+		}
+		return line
+	})
+}
+
+// namesOwner reports whether the owners part of an annotation, s, contains
+// a valid owner handle before any rationale.
+func namesOwner(s string) bool {
+	for _, tok := range strings.Fields(s) {
+		if tok == "--" {
+			return false
+		}
+		if scanning.ValidateOwner(tok) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// annotationStart returns the offset in line just past the first of
+// prefixes it has an annotation or directory directive for, or -1 if it has
+// none.
+func annotationStart(line string, prefixes []string) int {
+	for _, p := range prefixes {
+		for _, q := range []string{scanning.DirDirective(p), p} {
+			if start := scanning.AnnotationStart(line, q); start >= 0 {
+				return start
+			}
+		}
+	}
+	return -1
+}
+
+// edit applies fn to every line of every text file in fsys and returns the
+// files that changed. fn is told whether the line is from a directory owner
+// file. Lines are passed with their line ending.
+func edit(ctx context.Context, fsys fs.FS, opts scanning.Options, fn func(line string, dirOwner bool) string) ([]Edit, error) {
+	files, err := scanning.ListFiles(ctx, fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []Edit
+	for _, name := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info, err := fs.Lstat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() || info.Size() > maxFileSize {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.IndexByte(data[:min(len(data), 512)], 0) >= 0 {
+			continue
+		}
+
+		dirOwner := filepath.Base(name) == opts.DirOwnerFile
+		var out strings.Builder
+		for line := range strings.Lines(string(data)) {
+			out.WriteString(fn(line, dirOwner))
+		}
+		if out.String() != string(data) {
+			edits = append(edits, Edit{Path: name, Before: data, After: []byte(out.String())})
+		}
+	}
+	return edits, nil
+}
+
+// replaceToken replaces every whitespace-separated token of s equal to from
+// by to, keeping the whitespace between tokens. If stop is not empty, the
+// tokens from the first one equal to stop on are left alone.
+func replaceToken(s, from, to, stop string) string {
+	var b strings.Builder
+	for s != "" {
+		i := strings.IndexFunc(s, isNotSpace)
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		j := strings.IndexFunc(s, isSpace)
+		if j < 0 {
+			j = len(s)
+		}
+		if stop != "" && s[:j] == stop {
+			b.WriteString(s)
+			break
+		}
+		if s[:j] == from {
+			b.WriteString(to)
+		} else {
+			b.WriteString(s[:j])
+		}
+		s = s[j:]
+	}
+	return b.String()
+}
+
+func isSpace(r rune) bool    { return r == ' ' || r == '\t' || r == '\r' || r == '\n' }
+func isNotSpace(r rune) bool { return !isSpace(r) }
+
+// Apply writes the edits to the files below root, keeping their
+// permissions. Edits with a nil Before create new files.
+func Apply(root string, edits []Edit) error {
+	for _, e := range edits {
+		path := filepath.Join(root, filepath.FromSlash(e.Path))
+		if e.Before == nil {
+			// New files are committed next to the source files, so they get
+			// the same mode git checks source files out with.
+			if err := os.WriteFile(path, e.After, 0o644); err != nil { //nolint:gosec // G306: see above.
+				return err
+			}
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, e.After, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
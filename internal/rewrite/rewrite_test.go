@@ -0,0 +1,160 @@
+package rewrite_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/rewrite"
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+var defaultOpts = scanning.Options{Prefix: scanning.DefaultPrefix, DirOwnerFile: scanning.CodeOwnerFile}
+
+func TestRenameOwner(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"main.go":        {Data: []byte("// CodeOwner:  @old\t@other // keep spacing\r\npackage main // @old is not an annotation\r\n")},
+		"web/index.html": {Data: []byte("<!-- CodeOwner: @old-web @old -->\n<p>@old</p>\n")},
+		"web/.codeowner": {Data: []byte("# owners\n@old @web\n")},
+		"docs/guide.md":  {Data: []byte("CodeOwner: @docs\n")},
+		"bin/tool":       {Data: []byte("CodeOwner: @old\x00")},
+		"noeol.py":       {Data: []byte("# CodeOwner: @old")},
+		"pkg/doc.go":     {Data: []byte("// CodeOwner(dir): @old\npackage pkg\n")},
+		"deploy.sh":      {Data: []byte("# CodeOwner: @old -- took over from @old\n")},
+	}
+
+	edits, err := rewrite.RenameOwner(context.Background(), fsys, defaultOpts, "@old", "@org/new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"main.go":        "// CodeOwner:  @org/new\t@other // keep spacing\r\npackage main // @old is not an annotation\r\n",
+		"web/index.html": "<!-- CodeOwner: @old-web @org/new -->\n<p>@old</p>\n",
+		"web/.codeowner": "# owners\n@org/new @web\n",
+		"noeol.py":       "# CodeOwner: @org/new",
+		"pkg/doc.go":     "// CodeOwner(dir): @org/new\npackage pkg\n",
+		"deploy.sh":      "# CodeOwner: @org/new -- took over from @old\n",
+	}
+	if len(edits) != len(want) {
+		t.Errorf("expected %d edits, got %d: %v", len(want), len(edits), edits)
+	}
+	for _, e := range edits {
+		if string(e.Before) != string(fsys[e.Path].Data) {
+			t.Errorf("%s: Before differs from the original content", e.Path)
+		}
+		if got := string(e.After); got != want[e.Path] {
+			t.Errorf("%s:\ngot:  %q\nwant: %q", e.Path, got, want[e.Path])
+		}
+	}
+}
+
+func TestRenameOwner_CustomPrefix(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"a.go":   {Data: []byte("// Owner: @old\n// CodeOwner: @old\n")},
+		"OWNERS": {Data: []byte("@old\n")},
+	}
+	opts := scanning.Options{Prefix: "Owner:", DirOwnerFile: "OWNERS"}
+
+	edits, err := rewrite.RenameOwner(context.Background(), fsys, opts, "@old", "@new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]string{}
+	for _, e := range edits {
+		got[e.Path] = string(e.After)
+	}
+	if got["a.go"] != "// Owner: @new\n// CodeOwner: @old\n" || got["OWNERS"] != "@new\n" {
+		t.Errorf("unexpected edits: %q", got)
+	}
+}
+
+func TestRenameOwner_ExtraPrefixes(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"a.go": {Data: []byte("// Owner: @old\n// CodeOwner: @old\n// Maintainer: @old\n")},
+	}
+	opts := defaultOpts
+	opts.ExtraPrefixes = []string{"Owner:"}
+
+	edits, err := rewrite.RenameOwner(context.Background(), fsys, opts, "@old", "@new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(edits) != 1 || string(edits[0].After) != "// Owner: @new\n// CodeOwner: @new\n// Maintainer: @old\n" {
+		t.Errorf("unexpected edits: %v", edits)
+	}
+}
+
+func TestMigratePrefix(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"main.go":        {Data: []byte("// Owner:  @team -- why\r\npackage main // Owner: is mentioned here too\r\n")},
+		"web/index.html": {Data: []byte("<!-- Owner: @web -->\n")},
+		"web/.codeowner": {Data: []byte("# Owner: @not-an-annotation\n@web\n")},
+		"lib/a.go":       {Data: []byte("// CodeOwner: @lib\n// CodeOwner:@x Owner:@y\n")},
+		"noeol.py":       {Data: []byte("# Owner: @py")},
+		"pkg/doc.go":     {Data: []byte("// Owner(dir): @pkg\npackage pkg\n")},
+	}
+
+	edits, err := rewrite.MigratePrefix(context.Background(), fsys, defaultOpts, "Owner:", "CodeOwner:")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"main.go":        "// CodeOwner:  @team -- why\r\npackage main // Owner: is mentioned here too\r\n",
+		"web/index.html": "<!-- CodeOwner: @web -->\n",
+		"noeol.py":       "# CodeOwner: @py",
+		"pkg/doc.go":     "// CodeOwner(dir): @pkg\npackage pkg\n",
+	}
+	if len(edits) != len(want) {
+		t.Errorf("expected %d edits, got %d: %v", len(want), len(edits), edits)
+	}
+	for _, e := range edits {
+		if got := string(e.After); got != want[e.Path] {
+			t.Errorf("%s:\ngot:  %q\nwant: %q", e.Path, got, want[e.Path])
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(path, []byte("# CodeOwner: @old\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	edits, err := rewrite.RenameOwner(context.Background(), os.DirFS(dir), defaultOpts, "@old", "@new")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := rewrite.Apply(dir, edits); err != nil {
+		t.Fatalf("Apply error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "# CodeOwner: @new\n" {
+		t.Errorf("content = %q", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("mode = %v, want 0755", info.Mode().Perm())
+	}
+}
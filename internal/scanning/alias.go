@@ -0,0 +1,96 @@
+package scanning
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Aliases maps an owner handle used in annotations, such as @payments, to the
+// handles it stands for. Members may be aliases themselves.
+type Aliases map[string][]string
+
+// Validate checks that every alias and member is a valid owner handle, that
+// no alias is empty and that no alias refers back to itself.
+func (a Aliases) Validate() error {
+	for _, name := range slices.Sorted(maps.Keys(a)) {
+		if err := ValidateOwner(name); err != nil {
+			return fmt.Errorf("invalid alias: %w", err)
+		}
+		if len(a[name]) == 0 {
+			return fmt.Errorf("alias %s has no members", name)
+		}
+		for _, m := range a[name] {
+			if err := ValidateOwner(m); err != nil {
+				return fmt.Errorf("alias %s: %w", name, err)
+			}
+		}
+	}
+
+	state := make(map[string]visitState)
+	for _, name := range slices.Sorted(maps.Keys(a)) {
+		if err := a.visit(name, nil, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visitState tracks the aliases a cycle search has reached.
+type visitState int
+
+const (
+	unvisited visitState = iota
+	visiting
+	visited
+)
+
+// visit walks the aliases reachable from name depth-first, reporting a
+// cycle back to an alias in chain, the aliases leading to name.
+func (a Aliases) visit(name string, chain []string, state map[string]visitState) error {
+	chain = append(chain, name)
+	switch state[name] {
+	case visiting:
+		return fmt.Errorf("alias cycle: %s", strings.Join(chain[slices.Index(chain, name):], " -> "))
+	case visited:
+		return nil
+	case unvisited:
+	}
+	state[name] = visiting
+	for _, m := range a[name] {
+		if _, ok := a[m]; !ok {
+			continue
+		}
+		if err := a.visit(m, chain, state); err != nil {
+			return err
+		}
+	}
+	state[name] = visited
+	return nil
+}
+
+// Expand replaces every alias in owners by its members, recursively, keeping
+// the first occurrence of each resulting handle. The aliases must be valid.
+func (a Aliases) Expand(owners []string) []string {
+	if len(a) == 0 {
+		return owners
+	}
+	seen := make(map[string]struct{})
+	var out []string
+	var expand func(owner string)
+	expand = func(owner string) {
+		members, ok := a[owner]
+		if !ok {
+			out = appendUnique(seen, out, owner)
+			return
+		}
+		for _, m := range members {
+			expand(m)
+		}
+	}
+	for _, o := range owners {
+		expand(o)
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package scanning_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+func TestAliases_Expand(t *testing.T) {
+	t.Parallel()
+
+	aliases := scanning.Aliases{
+		"@payments": {"@org/payments-backend", "@org/payments-sre"},
+		"@money":    {"@payments", "@org/finance"},
+		"@sre":      {"@org/payments-sre"},
+	}
+
+	testCases := []struct {
+		name   string
+		owners []string
+		want   []string
+	}{
+		{name: "no aliases", owners: []string{"@a", "@b"}, want: []string{"@a", "@b"}},
+		{name: "alias", owners: []string{"@payments"}, want: []string{"@org/payments-backend", "@org/payments-sre"}},
+		{name: "nested alias", owners: []string{"@money"}, want: []string{"@org/payments-backend", "@org/payments-sre", "@org/finance"}},
+		{name: "duplicates removed", owners: []string{"@sre", "@payments", "@org/payments-sre"}, want: []string{"@org/payments-sre", "@org/payments-backend"}},
+		{name: "mixed", owners: []string{"@a", "@sre"}, want: []string{"@a", "@org/payments-sre"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := aliases.Expand(tc.owners); !slices.Equal(got, tc.want) {
+				t.Errorf("Expand(%v) = %v, want %v", tc.owners, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAliases_Validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		aliases scanning.Aliases
+		want    string
+	}{
+		{name: "nil", aliases: nil},
+		{name: "valid", aliases: scanning.Aliases{"@a": {"@b", "@org/c"}, "@b": {"@d"}}},
+		{name: "self cycle", aliases: scanning.Aliases{"@a": {"@a"}}, want: "alias cycle: @a -> @a"},
+		{name: "cycle", aliases: scanning.Aliases{"@a": {"@b"}, "@b": {"@c"}, "@c": {"@a"}}, want: "alias cycle: @a -> @b -> @c -> @a"},
+		{name: "invalid name", aliases: scanning.Aliases{"team": {"@b"}}, want: `invalid alias: invalid owner "team"`},
+		{name: "invalid member", aliases: scanning.Aliases{"@a": {"b"}}, want: `alias @a: invalid owner "b"`},
+		{name: "no members", aliases: scanning.Aliases{"@a": {}}, want: "alias @a has no members"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.aliases.Validate()
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestScanFS_Aliases(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"pay/.codeowner": {Data: []byte("@payments\n")},
+		"pay/api.go":     {Data: []byte("// CodeOwner: @payments @org/security\npackage pay\n")},
+	}
+	opts := scanning.Options{
+		Prefix:       scanning.DefaultPrefix,
+		DirOwnerFile: scanning.CodeOwnerFile,
+		Aliases:      scanning.Aliases{"@payments": {"@org/payments-backend", "@org/payments-sre"}},
+	}
+
+	got, err := scanning.ScanFS(context.Background(), fsys, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []scanning.Mapping{
+		{Path: "/pay/", Owners: []string{"@org/payments-backend", "@org/payments-sre"}},
+		{Path: "/pay/api.go", Owners: []string{"@org/payments-backend", "@org/payments-sre", "@org/security"}},
+	}
+	if !slices.EqualFunc(got, want, func(a, b scanning.Mapping) bool {
+		return a.Path == b.Path && slices.Equal(a.Owners, b.Owners)
+	}) {
+		t.Errorf("ScanFS:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	opts.Aliases = scanning.Aliases{"@payments": {"@payments"}}
+	if _, err := scanning.ScanFS(context.Background(), fsys, opts); err == nil {
+		t.Error("expected error for an alias cycle")
+	}
+}
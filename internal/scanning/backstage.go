@@ -0,0 +1,86 @@
+package scanning
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// BackstageFile is the name of Backstage catalog descriptor files.
+const BackstageFile = "catalog-info.yaml"
+
+// Backstage reads the owner of the entities in a Backstage catalog-info.yaml,
+// their spec.owner entity reference, as the owner of its directory. User
+// references, such as "user:jane", become the user's handle; group
+// references, such as "group:default/payments" or just "payments", become
+// the GitHub team given by Owner.
+type Backstage struct {
+	// Owner turns a group name into a handle, with {owner} standing for the
+	// name, e.g. "@org/{owner}". Empty prefixes the name with @.
+	Owner string `yaml:"owner,omitempty"`
+}
+
+// Validate checks that Owner, if set, contains {owner} and starts with @.
+func (b Backstage) Validate() error {
+	if b.Owner == "" {
+		return nil
+	}
+	if !strings.Contains(b.Owner, ownerPlaceholder) {
+		return fmt.Errorf("owner %q does not contain %s", b.Owner, ownerPlaceholder)
+	}
+	if err := ValidateOwner(strings.ReplaceAll(b.Owner, ownerPlaceholder, "team")); err != nil {
+		return fmt.Errorf("owner %q: %w", b.Owner, err)
+	}
+	return nil
+}
+
+func (Backstage) FileName() string { return BackstageFile }
+
+func (b Backstage) Owners(data []byte, _ Options) ([]string, error) {
+	seen := make(map[string]struct{})
+	var owners []string
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var entity struct {
+			Spec struct {
+				Owner string `yaml:"owner"`
+			} `yaml:"spec"`
+		}
+		if err := dec.Decode(&entity); err != nil {
+			if errors.Is(err, io.EOF) {
+				return owners, nil
+			}
+			return nil, err
+		}
+		if o := b.handle(entity.Spec.Owner); isValidOwner(o) && strings.HasPrefix(o, "@") {
+			owners = appendUnique(seen, owners, o)
+		}
+	}
+}
+
+// handle turns an entity reference into a handle, or "" if it has none.
+func (b Backstage) handle(ref string) string {
+	kind, name, ok := strings.Cut(ref, ":")
+	if !ok {
+		kind, name = "group", ref
+	}
+	if _, n, ok := strings.Cut(name, "/"); ok {
+		name = n
+	}
+	switch {
+	case name == "":
+		return ""
+	case strings.EqualFold(kind, "user"):
+		return "@" + name
+	case !strings.EqualFold(kind, "group"):
+		return ""
+	case b.Owner == "":
+		return "@" + name
+	default:
+		return strings.ReplaceAll(b.Owner, ownerPlaceholder, name)
+	}
+}
@@ -0,0 +1,210 @@
+package scanning
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cacheVersion is bumped whenever a change to the scanner could change the
+// owners found in an unchanged file, invalidating existing caches.
+const cacheVersion = 5
+
+// Cache remembers the owners found in each file so that later scans only
+// re-read files that changed. A file is unchanged if its size and
+// modification time match, or failing that, if its content hash matches.
+//
+// A Cache is safe for concurrent use by a single scan.
+type Cache struct {
+	path string
+	key  string
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	seen    map[string]bool
+}
+
+// cacheEntry is the cached result for one file.
+type cacheEntry struct {
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mtime"`
+	Hash     string    `json:"hash"`
+	Owners   []string  `json:"owners,omitempty"`
+	Comments []string  `json:"comments,omitempty"`
+	Prefixes []string  `json:"prefixes,omitempty"`
+	// DirOwners, DirComments and DirPrefixes are those of directory
+	// directives.
+	DirOwners   []string  `json:"dirOwners,omitempty"`
+	DirComments []string  `json:"dirComments,omitempty"`
+	DirPrefixes []string  `json:"dirPrefixes,omitempty"`
+	Scanned     time.Time `json:"scanned"`
+}
+
+// cacheFile is the on-disk form of a Cache.
+type cacheFile struct {
+	Key     string                `json:"key"`
+	Entries map[string]cacheEntry `json:"entries"`
+}
+
+// OpenCache loads the cache stored at path for scans with opts. A missing or
+// unreadable cache, or one written with different settings, starts empty.
+func OpenCache(path string, opts Options) *Cache {
+	c := &Cache{
+		path:    path,
+		key:     cacheKey(opts),
+		entries: make(map[string]cacheEntry),
+		seen:    make(map[string]bool),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	var f cacheFile
+	if err := json.Unmarshal(data, &f); err != nil || f.Key != c.key || f.Entries == nil {
+		return c
+	}
+	c.entries = f.Entries
+	return c
+}
+
+// cacheKey fingerprints the settings that affect what a file scan finds.
+func cacheKey(opts Options) string {
+	patterns := make([]string, len(opts.Patterns))
+	for i, p := range opts.Patterns {
+		patterns[i] = p.Regex + " " + p.Owner
+	}
+	return fmt.Sprintf("v%d prefixes=%q patterns=%q dirowner=%q header=%d lang=%t",
+		cacheVersion, opts.Prefixes(), patterns, opts.DirOwnerFile, opts.HeaderLines, opts.LanguageAnnotations)
+}
+
+// Save writes the cache back to disk, dropping files not seen since it was
+// opened.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f := cacheFile{Key: c.key, Entries: make(map[string]cacheEntry, len(c.seen))}
+	for name := range c.seen {
+		f.Entries[name] = c.entries[name]
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	// The cache records the owners of files in repositories that may be
+	// private, so only the user may read it.
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash or a concurrent scan never
+	// leaves a torn cache.
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// annotations returns the cached scan result.
+func (e cacheEntry) annotations() annotations {
+	return annotations{
+		owners:      e.Owners,
+		comments:    e.Comments,
+		prefixes:    e.Prefixes,
+		dirOwners:   e.DirOwners,
+		dirComments: e.DirComments,
+		dirPrefixes: e.DirPrefixes,
+	}
+}
+
+// lookupStat returns the cached annotations of name if its size and modification
+// time are unchanged. Modification times that are not clearly older than the
+// scan that cached them are not trusted, since the file may have been
+// written again within the filesystem's timestamp resolution.
+func (c *Cache) lookupStat(name string, info fs.FileInfo) (annotations, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[name]
+	if !ok || info.ModTime().IsZero() || e.Size != info.Size() || !e.ModTime.Equal(info.ModTime()) {
+		return annotations{}, false
+	}
+	if !e.ModTime.Before(e.Scanned.Add(-time.Second)) {
+		return annotations{}, false
+	}
+	c.seen[name] = true
+	return e.annotations(), true
+}
+
+// lookupHash returns the cached annotations of name if its content is unchanged,
+// refreshing the stored size and modification time.
+func (c *Cache) lookupHash(name string, info fs.FileInfo, hash string) (annotations, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[name]
+	if !ok || e.Hash != hash {
+		return annotations{}, false
+	}
+	e.Size, e.ModTime, e.Scanned = info.Size(), info.ModTime(), time.Now()
+	c.entries[name] = e
+	c.seen[name] = true
+	return e.annotations(), true
+}
+
+// store records the annotations found in name.
+func (c *Cache) store(name string, info fs.FileInfo, hash string, found annotations) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[name] = cacheEntry{
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		Hash:        hash,
+		Owners:      found.owners,
+		Comments:    found.comments,
+		Prefixes:    found.prefixes,
+		DirOwners:   found.dirOwners,
+		DirComments: found.dirComments,
+		DirPrefixes: found.dirPrefixes,
+		Scanned:     time.Now(),
+	}
+	c.seen[name] = true
+}
+
+// hashContent returns the hex SHA-256 of data.
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// DefaultCachePath returns the cache file used for scans of root: a file
+// named after the absolute root in the user's cache directory.
+func DefaultCachePath(root string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, "codeowner", hex.EncodeToString(sum[:8])+".json"), nil
+}
@@ -0,0 +1,159 @@
+package scanning_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+// oldTime is a modification time clearly older than any scan in these tests,
+// so cached entries are trusted on size and mtime alone.
+var oldTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeOld writes content to name under dir and backdates it to oldTime.
+func writeOld(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// cachedScan scans dir through the cache stored at cachePath and saves it.
+func cachedScan(t *testing.T, dir, cachePath string, opts scanning.Options) []scanning.Mapping {
+	t.Helper()
+
+	opts.Cache = scanning.OpenCache(cachePath, opts)
+	mappings, err := scanning.Scan(dir, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := opts.Cache.Save(); err != nil {
+		t.Fatalf("saving cache: %v", err)
+	}
+	return mappings
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	opts := scanning.Options{Prefix: scanning.DefaultPrefix, DirOwnerFile: scanning.CodeOwnerFile}
+
+	testCases := []struct {
+		name string
+		// change modifies the tree after the first scan.
+		change func(t *testing.T, dir string)
+		// rescan is the options of the second scan.
+		rescan scanning.Options
+		want   string
+	}{
+		{
+			// Rewriting a file without changing its size or mtime is invisible
+			// to the cache, which proves the file was not read again.
+			name:   "unchanged stat reuses cached owners",
+			change: func(t *testing.T, dir string) { writeOld(t, dir, "main.go", "// CodeOwner: @bbb\n") },
+			rescan: opts,
+			want:   "@aaa",
+		},
+		{
+			name: "changed mtime rescans file",
+			change: func(t *testing.T, dir string) {
+				writeOld(t, dir, "main.go", "// CodeOwner: @bbb\n")
+				later := oldTime.Add(time.Hour)
+				if err := os.Chtimes(filepath.Join(dir, "main.go"), later, later); err != nil {
+					t.Fatal(err)
+				}
+			},
+			rescan: opts,
+			want:   "@bbb",
+		},
+		{
+			name:   "changed size rescans file",
+			change: func(t *testing.T, dir string) { writeOld(t, dir, "main.go", "// CodeOwner: @bbbb\n") },
+			rescan: opts,
+			want:   "@bbbb",
+		},
+		{
+			name:   "changed prefix invalidates cache",
+			change: func(t *testing.T, dir string) { writeOld(t, dir, "main.go", "// CodeOwner: @bbb\n") },
+			rescan: scanning.Options{Prefix: "Owner:", DirOwnerFile: scanning.CodeOwnerFile},
+			want:   "",
+		},
+		{
+			name:   "changed dirowner invalidates cache",
+			change: func(t *testing.T, dir string) { writeOld(t, dir, "main.go", "// CodeOwner: @bbb\n") },
+			rescan: scanning.Options{Prefix: scanning.DefaultPrefix, DirOwnerFile: "OWNERS"},
+			want:   "@bbb",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			cachePath := filepath.Join(t.TempDir(), "cache", "scan.json")
+			writeOld(t, dir, "main.go", "// CodeOwner: @aaa\n")
+
+			first := cachedScan(t, dir, cachePath, opts)
+			if len(first) != 1 || first[0].Owners[0] != "@aaa" {
+				t.Fatalf("first scan: got %v", first)
+			}
+
+			tc.change(t, dir)
+			second := cachedScan(t, dir, cachePath, tc.rescan)
+
+			got := ""
+			if len(second) == 1 {
+				got = second[0].Owners[0]
+			}
+			if got != tc.want {
+				t.Errorf("second scan: got owner %q, want %q (mappings %v)", got, tc.want, second)
+			}
+		})
+	}
+}
+
+func TestCache_MatchesUncachedScanOnTestdata(t *testing.T) {
+	t.Parallel()
+
+	opts := scanning.Options{Prefix: scanning.DefaultPrefix, DirOwnerFile: scanning.CodeOwnerFile}
+	want, err := scanning.Scan(testdataDir(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cachePath := filepath.Join(t.TempDir(), "scan.json")
+	for run := range 2 {
+		got := cachedScan(t, testdataDir(), cachePath, opts)
+		if !slices.EqualFunc(got, want, func(a, b scanning.Mapping) bool {
+			return a.Path == b.Path && slices.Equal(a.Owners, b.Owners)
+		}) {
+			t.Errorf("cached scan %d differs from uncached scan:\ngot:  %v\nwant: %v", run, got, want)
+		}
+	}
+}
+
+func TestOpenCache_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeOld(t, dir, "main.go", "// CodeOwner: @aaa\n")
+	cachePath := filepath.Join(t.TempDir(), "scan.json")
+	if err := os.WriteFile(cachePath, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got := cachedScan(t, dir, cachePath, scanning.Options{Prefix: scanning.DefaultPrefix, DirOwnerFile: scanning.CodeOwnerFile})
+	if len(got) != 1 || got[0].Owners[0] != "@aaa" {
+		t.Errorf("got %v, want /main.go @aaa", got)
+	}
+}
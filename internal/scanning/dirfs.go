@@ -0,0 +1,66 @@
+package scanning
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// osFS is the set of interfaces implemented by os.DirFS.
+type osFS interface {
+	fs.ReadDirFS
+	fs.ReadFileFS
+	fs.StatFS
+	fs.ReadLinkFS
+}
+
+// dirFS is os.DirFS with absolute symlink targets inside the root rewritten
+// as relative ones, so that resolveLink can follow them.
+type dirFS struct {
+	osFS
+	roots []string // the absolute root, and its resolved form if different
+}
+
+// newDirFS returns the filesystem used to scan the directory root.
+func newDirFS(root string) (fs.FS, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	roots := []string{abs}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil && resolved != abs {
+		roots = append(roots, resolved)
+	}
+	fsys, ok := os.DirFS(root).(osFS)
+	if !ok {
+		return nil, fmt.Errorf("os.DirFS(%q) does not support reading directories, files, stats and links", root)
+	}
+	return dirFS{osFS: fsys, roots: roots}, nil
+}
+
+// ReadLink returns the slash-separated target of the symlink name. Absolute
+// targets inside the root are returned relative to the link's directory.
+func (d dirFS) ReadLink(name string) (string, error) {
+	target, err := d.osFS.ReadLink(name)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		return filepath.ToSlash(target), nil
+	}
+	for _, root := range d.roots {
+		rel, err := filepath.Rel(root, target)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		up := strings.Repeat("../", strings.Count(path.Dir(name), "/"))
+		if path.Dir(name) != "." {
+			up += "../"
+		}
+		return up + filepath.ToSlash(rel), nil
+	}
+	return filepath.ToSlash(target), nil
+}
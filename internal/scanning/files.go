@@ -0,0 +1,40 @@
+package scanning
+
+import (
+	"context"
+	"io/fs"
+)
+
+// ListFiles returns the slash-separated paths of every file in fsys that a
+// scan with opts would visit, whether or not it has an annotation. Paths
+// keep walk order. Symlinks are listed as files and never followed, matching
+// how git and GitHub see them.
+func ListFiles(ctx context.Context, fsys fs.FS, opts Options) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		if opts.Filter != nil && !opts.Filter(name, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		files = append(files, name)
+		return nil
+	})
+	return files, err
+}
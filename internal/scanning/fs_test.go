@@ -0,0 +1,174 @@
+package scanning_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+// testdataFS loads the testdata directory into an in-memory filesystem.
+func testdataFS(t *testing.T) fstest.MapFS {
+	t.Helper()
+
+	root := testdataDir()
+	fsys := fstest.MapFS{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		fsys[filepath.ToSlash(rel)] = &fstest.MapFile{Data: data, Mode: 0o644}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fsys
+}
+
+func TestScanFS_MatchesScanOnTestdata(t *testing.T) {
+	t.Parallel()
+
+	want, err := scanning.ParseDir(testdataDir(), scanning.DefaultPrefix, scanning.CodeOwnerFile)
+	if err != nil {
+		t.Fatalf("ParseDir error: %v", err)
+	}
+
+	got, err := scanning.ScanFS(context.Background(), testdataFS(t), scanning.Options{
+		Prefix:       scanning.DefaultPrefix,
+		DirOwnerFile: scanning.CodeOwnerFile,
+	})
+	if err != nil {
+		t.Fatalf("ScanFS error: %v", err)
+	}
+
+	if !slices.EqualFunc(got, want, func(a, b scanning.Mapping) bool {
+		return a.Path == b.Path && slices.Equal(a.Owners, b.Owners)
+	}) {
+		t.Errorf("ScanFS differs from ParseDir:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestParseFileFS_Testdata(t *testing.T) {
+	t.Parallel()
+
+	fsys := testdataFS(t)
+	dir := testdataDir()
+
+	for name := range fsys {
+		if filepath.Base(name) == scanning.CodeOwnerFile {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := scanning.ParseFileFS(fsys, name, scanning.DefaultPrefix)
+			if err != nil {
+				t.Fatalf("ParseFileFS(%s) error: %v", name, err)
+			}
+			want, err := scanning.ParseFile(filepath.Join(dir, filepath.FromSlash(name)), scanning.DefaultPrefix)
+			if err != nil {
+				t.Fatalf("ParseFile(%s) error: %v", name, err)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("ParseFileFS(%s) = %v, want %v", name, got, want)
+			}
+		})
+	}
+}
+
+func TestParseCodeOwnerFileFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"team/.codeowner": {Data: []byte("@team-a @team-b\n@team-a\n")},
+	}
+
+	got, err := scanning.ParseCodeOwnerFileFS(fsys, "team/.codeowner")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"@team-a", "@team-b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := scanning.ParseCodeOwnerFileFS(fsys, "missing/.codeowner"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestScanFS_FollowSymlinks(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"shared/.codeowner":  {Data: []byte("@shared-team\n")},
+		"shared/config.yaml": {Data: []byte("# CodeOwner: @config-team\n")},
+		"shared/loop":        {Data: []byte(".."), Mode: fs.ModeSymlink},
+		"svc/config":         {Data: []byte("../shared"), Mode: fs.ModeSymlink},
+		"svc/escape":         {Data: []byte("../../outside"), Mode: fs.ModeSymlink},
+		"svc/absolute":       {Data: []byte("/etc"), Mode: fs.ModeSymlink},
+	}
+
+	mappings, err := scanning.ScanFS(context.Background(), fsys, scanning.Options{
+		Prefix:         scanning.DefaultPrefix,
+		DirOwnerFile:   scanning.CodeOwnerFile,
+		FollowSymlinks: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, m := range mappings {
+		got = append(got, m.Path)
+	}
+	want := []string{
+		"/shared/",
+		"/shared/config.yaml",
+		"/svc/config/",
+		"/svc/config/config.yaml",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"main.go":          {Data: []byte("package main\n")},
+		"api/.codeowner":   {Data: []byte("@api\n")},
+		"api/handler.go":   {Data: []byte("// CodeOwner: @handler\n")},
+		"vendor/lib.go":    {Data: []byte("package lib\n")},
+		".git/config":      {Data: []byte("[core]\n")},
+		"link":             {Data: []byte("api"), Mode: fs.ModeSymlink},
+		"docs/empty/.keep": {},
+	}
+
+	got, err := scanning.ListFiles(context.Background(), fsys, scanning.Options{
+		Filter: func(path string, isDir bool) bool { return path != "vendor" },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"api/.codeowner", "api/handler.go", "docs/empty/.keep", "link", "main.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ListFiles = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package scanning
+
+import "strings"
+
+// DefaultHeaderLines is the suggested line limit for header-only scanning.
+const DefaultHeaderLines = 50
+
+// blockComment pairs the opening and closing markers of a block comment.
+type blockComment struct {
+	open, close string
+}
+
+// blockComments lists the block comment styles recognised in file headers.
+var blockComments = []blockComment{
+	{"/*", "*/"},
+	{"<!--", "-->"},
+	{"{-", "-}"},
+	{"(*", "*)"},
+	{`"""`, `"""`},
+	{"'''", "'''"},
+}
+
+// lineComments lists the line comment markers recognised in file headers.
+// "*" covers the continuation lines of Javadoc-style block comments.
+var lineComments = []string{"//", "#", "--", ";", "%", "*"}
+
+// preambles lists non-comment lines that may precede a file's header
+// comments, such as language open tags and XML declarations.
+var preambles = []string{"<?php", "<?xml", "<!DOCTYPE", "<!doctype"}
+
+// headerState tracks whether a scan is still inside a file's leading
+// comment block.
+type headerState struct {
+	maxLines int
+	lines    int
+	blockEnd string // closing marker of the open block comment, or ""
+}
+
+// inHeader reports whether line still belongs to the file header. The header
+// ends at the first line of code or once maxLines lines have been read.
+func (h *headerState) inHeader(line string) bool {
+	h.lines++
+	if h.lines > h.maxLines {
+		return false
+	}
+
+	trimmed := strings.TrimSpace(line)
+	if h.blockEnd != "" {
+		if strings.Contains(trimmed, h.blockEnd) {
+			h.blockEnd = ""
+		}
+		return true
+	}
+	if trimmed == "" {
+		return true
+	}
+	for _, p := range preambles {
+		if strings.HasPrefix(trimmed, p) {
+			return true
+		}
+	}
+	for _, bc := range blockComments {
+		if strings.HasPrefix(trimmed, bc.open) {
+			if !strings.Contains(trimmed[len(bc.open):], bc.close) {
+				h.blockEnd = bc.close
+			}
+			return true
+		}
+	}
+	for _, marker := range lineComments {
+		if strings.HasPrefix(trimmed, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package scanning
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// docOwnerKey introduces the owners in a language's documentation idioms,
+// such as a Go package doc comment. "Owners:" is accepted too.
+const docOwnerKey = "Owner:"
+
+// languageExtractor finds owners in the idioms of one language. It is fed
+// a file line by line and returns the annotations each line completes,
+// normalized to start with docOwnerKey.
+type languageExtractor interface {
+	line(s string) []string
+}
+
+// newLanguageExtractor returns the extractor for the language of the file
+// name, selected by its extension, or nil if there is none.
+func newLanguageExtractor(name string) languageExtractor {
+	switch path.Ext(name) {
+	case ".go":
+		return &goExtractor{}
+	case ".py", ".pyi":
+		return &pythonExtractor{}
+	}
+	return nil
+}
+
+// docOwnerLine returns the annotation in a line of documentation text, or
+// "" if it has none.
+func docOwnerLine(text string) string {
+	text = strings.TrimSpace(text)
+	for _, key := range []string{docOwnerKey, "Owners:"} {
+		if rest, ok := strings.CutPrefix(text, key); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			return docOwnerKey + " " + strings.TrimSpace(rest)
+		}
+	}
+	return ""
+}
+
+// docOwnerLines returns the annotations in lines of documentation text.
+func docOwnerLines(lines []string) []string {
+	var found []string
+	for _, l := range lines {
+		if a := docOwnerLine(l); a != "" {
+			found = append(found, a)
+		}
+	}
+	return found
+}
+
+// goExtractor reads "Owner: @team" lines in a Go package doc comment, the
+// comment starting with "Package name" right before the package clause.
+type goExtractor struct {
+	doc     []string // text of the current comment
+	inLine  bool     // the previous line was a // comment
+	inBlock bool     // inside a /* */ comment
+	done    bool     // past the package clause
+}
+
+func (g *goExtractor) line(s string) []string {
+	if g.done {
+		return nil
+	}
+	t := strings.TrimSpace(s)
+	if g.inBlock {
+		before, _, closed := strings.Cut(t, "*/")
+		g.doc = append(g.doc, before)
+		g.inBlock = !closed
+		return nil
+	}
+
+	wasLine := g.inLine
+	g.inLine = false
+	switch {
+	case strings.HasPrefix(t, "//"):
+		if !wasLine {
+			g.doc = nil
+		}
+		g.doc = append(g.doc, t[2:])
+		g.inLine = true
+	case strings.HasPrefix(t, "/*"):
+		before, _, closed := strings.Cut(t[2:], "*/")
+		g.doc = []string{before}
+		g.inBlock = !closed
+	case strings.HasPrefix(t, "package "):
+		g.done = true
+		i := slices.IndexFunc(g.doc, func(l string) bool { return strings.TrimSpace(l) != "" })
+		if i >= 0 && strings.HasPrefix(strings.TrimSpace(g.doc[i]), "Package ") {
+			return docOwnerLines(g.doc)
+		}
+	default:
+		// Blank lines and build constraints separate other comments from
+		// the package doc.
+		g.doc = nil
+	}
+	return nil
+}
+
+var (
+	// pythonOwnerVar matches a module-level __owner__ or __owners__
+	// assignment, capturing the value.
+	pythonOwnerVar = regexp.MustCompile(`^__owners?__\s*(?::[^=]*)?=\s*(.*)$`)
+	// pythonString matches a single-line string literal, capturing its text.
+	pythonString = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+	// pythonDocstring matches the opening quotes of a docstring, with an
+	// optional string prefix, capturing the quotes.
+	pythonDocstring = regexp.MustCompile(`^[rRuU]?("""|''')`)
+)
+
+// pythonExtractor reads "Owner: @team" lines in a Python module docstring
+// and the handles assigned to a module-level __owner__ or __owners__
+// variable, a string or a list of strings on one line.
+type pythonExtractor struct {
+	quote   string // the closing quotes of the open docstring
+	docDone bool   // past the module docstring
+}
+
+func (p *pythonExtractor) line(s string) []string {
+	if p.quote != "" {
+		before, _, closed := strings.Cut(s, p.quote)
+		if closed {
+			p.quote = ""
+			p.docDone = true
+		}
+		return docOwnerLines([]string{before})
+	}
+
+	if m := pythonOwnerVar.FindStringSubmatch(s); m != nil {
+		p.docDone = true
+		var owners []string
+		for _, lit := range pythonString.FindAllStringSubmatch(m[1], -1) {
+			owners = append(owners, lit[1]+lit[2])
+		}
+		if len(owners) == 0 {
+			return nil
+		}
+		return []string{docOwnerKey + " " + strings.Join(owners, " ")}
+	}
+
+	t := strings.TrimSpace(s)
+	if p.docDone || t == "" || strings.HasPrefix(t, "#") {
+		return nil
+	}
+	// The first statement of the module is its docstring, if it is a string.
+	m := pythonDocstring.FindStringSubmatch(t)
+	if m == nil {
+		p.docDone = true
+		return nil
+	}
+	rest := t[len(m[0]):]
+	before, _, closed := strings.Cut(rest, m[1])
+	if closed {
+		p.docDone = true
+	} else {
+		p.quote = m[1]
+	}
+	return docOwnerLines([]string{before})
+}
@@ -0,0 +1,135 @@
+package scanning_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+func TestScan_LanguageAnnotations(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		file     string
+		content  string
+		want     string
+		comments string
+	}{
+		{
+			name:    "go package doc",
+			file:    "doc.go",
+			content: "// Package billing charges customers.\n//\n// Owner: @payments @sre\npackage billing\n",
+			want:    "[@payments @sre]",
+		},
+		{
+			name:     "go package doc after build constraint",
+			file:     "doc_linux.go",
+			content:  "//go:build linux\n\n// Package billing charges customers.\n//\n// Owners: @payments -- billing on-call\npackage billing\n",
+			want:     "[@payments]",
+			comments: "[billing on-call]",
+		},
+		{
+			name:    "go block package doc",
+			file:    "doc.go",
+			content: "/*\nPackage billing charges customers.\n\nOwner: @payments\n*/\npackage billing\n",
+			want:    "[@payments]",
+		},
+		{
+			name:    "go comment that is not the package doc",
+			file:    "main.go",
+			content: "// Owner: @payments\n\n// Package billing charges customers.\npackage billing\n\n// Owner: @later\nfunc f() {}\n",
+		},
+		{
+			name:    "go doc of a declaration",
+			file:    "billing.go",
+			content: "package billing\n\n// Charge charges a customer.\n//\n// Owner: @payments\nfunc Charge() {}\n",
+		},
+		{
+			name:    "python module variable",
+			file:    "billing.py",
+			content: "import os\n\n__owner__ = \"@payments\"\n",
+			want:    "[@payments]",
+		},
+		{
+			name:    "python module variable list",
+			file:    "billing.py",
+			content: "__owners__: list[str] = ['@payments', \"@sre\"]  # see docs\n",
+			want:    "[@payments @sre]",
+		},
+		{
+			name:    "python nested variable",
+			file:    "billing.py",
+			content: "class Billing:\n    __owner__ = \"@payments\"\n",
+		},
+		{
+			name:    "python module docstring",
+			file:    "billing.py",
+			content: "#!/usr/bin/env python3\n\"\"\"Charge customers.\n\nOwner: @payments\n\"\"\"\n\ndef f():\n    \"\"\"Owner: @later\"\"\"\n",
+			want:    "[@payments]",
+		},
+		{
+			name:    "python single-line docstring",
+			file:    "billing.pyi",
+			content: "r'''Owner: @payments'''\n",
+			want:    "[@payments]",
+		},
+		{
+			name:    "other languages",
+			file:    "billing.rb",
+			content: "# Owner: @payments\n__owner__ = \"@payments\"\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fsys := fstest.MapFS{tc.file: {Data: []byte(tc.content)}}
+			opts := scanning.Options{Prefix: scanning.DefaultPrefix, LanguageAnnotations: true}
+			mappings, err := scanning.ScanFS(context.Background(), fsys, opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got, comments string
+			if len(mappings) > 0 {
+				got = fmt.Sprint(mappings[0].Owners)
+				if len(mappings[0].Comments) > 0 {
+					comments = fmt.Sprint(mappings[0].Comments)
+				}
+			}
+			if got != tc.want || comments != tc.comments {
+				t.Errorf("got owners %q comments %q, want %q %q", got, comments, tc.want, tc.comments)
+			}
+
+			// Without the option only the prefix is read.
+			opts.LanguageAnnotations = false
+			if mappings, err := scanning.ScanFS(context.Background(), fsys, opts); err != nil || len(mappings) != 0 {
+				t.Errorf("without language annotations: got %v, %v", mappings, err)
+			}
+		})
+	}
+}
+
+func TestScan_LanguageAnnotationsInHeader(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"doc.go":     {Data: []byte("// Package foo does things.\n//\n// Owner: @go-team\npackage foo\n")},
+		"billing.py": {Data: []byte("\"\"\"Charge customers.\n\nOwner: @payments\n\"\"\"\n\nimport os\n")},
+		"late.go":    {Data: []byte("package foo\n\n// Owner: @late\nfunc f() {}\n")},
+	}
+	opts := scanning.Options{Prefix: scanning.DefaultPrefix, LanguageAnnotations: true, HeaderLines: scanning.DefaultHeaderLines}
+	mappings, err := scanning.ScanFS(context.Background(), fsys, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "[{/billing.py [@payments] [] []} {/doc.go [@go-team] [] []}]"
+	if got := fmt.Sprint(mappings); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,219 @@
+package scanning
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// MetadataReader reads the owners a package declares in its manifest, such
+// as package.json, so that they become a rule for the package's directory.
+type MetadataReader interface {
+	// FileName is the name of the manifest files the reader handles.
+	FileName() string
+	// Owners returns the owner handles declared in a manifest's content.
+	// opts are the options of the scan.
+	Owners(data []byte, opts Options) ([]string, error)
+}
+
+// metadataReaders are the built-in readers, by manifest file name.
+var metadataReaders = []MetadataReader{packageJSON{}, cargoTOML{}, pyprojectTOML{}, goMod{}, Backstage{}}
+
+// MetadataFiles lists the manifest file names that have a built-in reader.
+func MetadataFiles() []string {
+	names := make([]string, len(metadataReaders))
+	for i, r := range metadataReaders {
+		names[i] = r.FileName()
+	}
+	return names
+}
+
+// BuiltinMetadataReaders returns the built-in readers for manifests with the
+// given file names.
+func BuiltinMetadataReaders(fileNames ...string) ([]MetadataReader, error) {
+	readers := make([]MetadataReader, 0, len(fileNames))
+	for _, name := range fileNames {
+		i := slices.IndexFunc(metadataReaders, func(r MetadataReader) bool { return r.FileName() == name })
+		if i < 0 {
+			return nil, fmt.Errorf("no metadata reader for %q, supported files are %s", name, strings.Join(MetadataFiles(), ", "))
+		}
+		readers = append(readers, metadataReaders[i])
+	}
+	return readers, nil
+}
+
+// metadataReader returns the reader in opts for the file name, or nil.
+func metadataReader(opts Options, name string) MetadataReader {
+	for _, r := range opts.MetadataReaders {
+		if r.FileName() == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// parseMetadataEntry reads the manifest name with r, returning a
+// directory-level Mapping for the directory of logical, like a directory
+// owner file. A manifest r cannot parse yields a *metadataError.
+func parseMetadataEntry(fsys fs.FS, name, logical string, r MetadataReader, opts Options) (Mapping, bool, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return Mapping{}, false, err
+	}
+	owners, err := r.Owners(data, opts)
+	if err != nil {
+		return Mapping{}, false, &metadataError{path: logical, err: err}
+	}
+	if len(owners) == 0 {
+		return Mapping{}, false, nil
+	}
+	return Mapping{Path: dirPath(logical), Owners: owners}, true, nil
+}
+
+// metadataError is a manifest a reader could not parse. Manifests are only
+// an extra source of owners, so the scan skips them with a warning instead
+// of failing.
+type metadataError struct {
+	path string
+	err  error
+}
+
+func (e *metadataError) Error() string {
+	return fmt.Sprintf("skipping %s: %v", e.path, e.err)
+}
+
+func (e *metadataError) Unwrap() error { return e.err }
+
+// handles returns the distinct valid owner handles among the
+// whitespace-separated tokens of values. Other tokens, such as names and
+// email addresses of authors, are skipped.
+func handles(values ...string) []string {
+	seen := make(map[string]struct{})
+	var owners []string
+	for _, v := range values {
+		for _, tok := range strings.Fields(v) {
+			if strings.HasPrefix(tok, "@") && isValidOwner(tok) {
+				owners = appendUnique(seen, owners, tok)
+			}
+		}
+	}
+	return owners
+}
+
+// packageJSON reads the "codeowners" field of an npm package.json, a string
+// or a list of strings, falling back to its "author".
+type packageJSON struct{}
+
+func (packageJSON) FileName() string { return "package.json" }
+
+func (packageJSON) Owners(data []byte, _ Options) ([]string, error) {
+	var pkg struct {
+		CodeOwners json.RawMessage `json:"codeowners"`
+		Author     json.RawMessage `json:"author"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	if len(pkg.CodeOwners) > 0 {
+		var list []string
+		if err := json.Unmarshal(pkg.CodeOwners, &list); err == nil {
+			return handles(list...), nil
+		}
+		var s string
+		if err := json.Unmarshal(pkg.CodeOwners, &s); err != nil {
+			return nil, fmt.Errorf("codeowners must be a string or a list of strings")
+		}
+		return handles(s), nil
+	}
+
+	// The author is a "Name <email> (url)" string or an object with a name.
+	var s string
+	if json.Unmarshal(pkg.Author, &s) == nil {
+		return handles(s), nil
+	}
+	var author struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(pkg.Author, &author) == nil {
+		return handles(author.Name), nil
+	}
+	return nil, nil
+}
+
+// cargoTOML reads the owners list of the [package.metadata.codeowner] table
+// of a Rust Cargo.toml, falling back to the package authors.
+type cargoTOML struct{}
+
+func (cargoTOML) FileName() string { return "Cargo.toml" }
+
+func (cargoTOML) Owners(data []byte, _ Options) ([]string, error) {
+	var manifest struct {
+		Package struct {
+			Authors  []string `toml:"authors"`
+			Metadata struct {
+				CodeOwner struct {
+					Owners []string `toml:"owners"`
+				} `toml:"codeowner"`
+			} `toml:"metadata"`
+		} `toml:"package"`
+	}
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	if owners := manifest.Package.Metadata.CodeOwner.Owners; owners != nil {
+		return handles(owners...), nil
+	}
+	return handles(manifest.Package.Authors...), nil
+}
+
+// pyprojectTOML reads the owners list of the [tool.codeowner] table of a
+// Python pyproject.toml, falling back to the names of the project authors
+// and maintainers.
+type pyprojectTOML struct{}
+
+func (pyprojectTOML) FileName() string { return "pyproject.toml" }
+
+func (pyprojectTOML) Owners(data []byte, _ Options) ([]string, error) {
+	type person struct {
+		Name string `toml:"name"`
+	}
+	var manifest struct {
+		Tool struct {
+			CodeOwner struct {
+				Owners []string `toml:"owners"`
+			} `toml:"codeowner"`
+		} `toml:"tool"`
+		Project struct {
+			Authors     []person `toml:"authors"`
+			Maintainers []person `toml:"maintainers"`
+		} `toml:"project"`
+	}
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	if owners := manifest.Tool.CodeOwner.Owners; owners != nil {
+		return handles(owners...), nil
+	}
+	var names []string
+	for _, p := range slices.Concat(manifest.Project.Authors, manifest.Project.Maintainers) {
+		names = append(names, p.Name)
+	}
+	return handles(names...), nil
+}
+
+// goMod reads the annotations in the comments of a Go go.mod file, which
+// then own the whole module rather than just the go.mod file.
+type goMod struct{}
+
+func (goMod) FileName() string { return "go.mod" }
+
+func (goMod) Owners(data []byte, opts Options) ([]string, error) {
+	found, err := scanOwners(bufio.NewReader(bytes.NewReader(data)), "go.mod", opts)
+	return found.owners, err
+}
@@ -0,0 +1,169 @@
+package scanning_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+func TestScanFS_Metadata(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{name: "package.json codeowners list", file: "package.json", content: `{"name": "web", "codeowners": ["@frontend", "@org/design"]}`, want: "[@frontend @org/design]"},
+		{name: "package.json codeowners string", file: "package.json", content: `{"codeowners": "@frontend @frontend"}`, want: "[@frontend]"},
+		{name: "package.json author string", file: "package.json", content: `{"author": "@frontend <frontend@example.com>"}`, want: "[@frontend]"},
+		{name: "package.json author object", file: "package.json", content: `{"author": {"name": "@frontend", "email": "frontend@example.com"}}`, want: "[@frontend]"},
+		{name: "package.json author name", file: "package.json", content: `{"author": "Jane Doe <jane@example.com>"}`},
+		{name: "package.json codeowners before author", file: "package.json", content: `{"codeowners": ["@frontend"], "author": "@jane"}`, want: "[@frontend]"},
+		{name: "Cargo.toml metadata", file: "Cargo.toml", content: "[package]\nname = \"core\"\nauthors = [\"@jane\"]\n\n[package.metadata.codeowner]\nowners = [\"@rust\"]\n", want: "[@rust]"},
+		{name: "Cargo.toml authors", file: "Cargo.toml", content: "[package]\nauthors = [\"@rust\", \"Jane <jane@example.com>\"]\n", want: "[@rust]"},
+		{name: "pyproject.toml tool table", file: "pyproject.toml", content: "[tool.codeowner]\nowners = [\"@python\"]\n", want: "[@python]"},
+		{name: "pyproject.toml authors and maintainers", file: "pyproject.toml", content: "[project]\nauthors = [{name = \"@python\"}]\nmaintainers = [{name = \"@data\", email = \"data@example.com\"}]\n", want: "[@python @data]"},
+		{name: "catalog-info.yaml group", file: "catalog-info.yaml", content: "apiVersion: backstage.io/v1alpha1\nkind: Component\nmetadata:\n  name: web\nspec:\n  owner: group:default/web-team\n", want: "[@web-team]"},
+		{name: "catalog-info.yaml entities", file: "catalog-info.yaml", content: "kind: Component\nspec:\n  owner: web-team\n---\nkind: API\nspec:\n  owner: user:jane\n---\nkind: Resource\nspec:\n  owner: system:shop\n", want: "[@web-team @jane]"},
+		{name: "go.mod comment", file: "go.mod", content: "// CodeOwner: @go\nmodule example.com/svc\n", want: "[@go]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fsys := fstest.MapFS{"pkg/" + tc.file: {Data: []byte(tc.content)}}
+			readers, err := scanning.BuiltinMetadataReaders(tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mappings, err := scanning.ScanFS(context.Background(), fsys, scanning.Options{Prefix: scanning.DefaultPrefix, MetadataReaders: readers})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got string
+			for _, m := range mappings {
+				switch m.Path {
+				case "/pkg/":
+					got = fmt.Sprint(m.Owners)
+				case "/pkg/" + tc.file:
+					t.Errorf("unexpected rule for the manifest itself: %v", m)
+				}
+			}
+			if got != tc.want {
+				t.Errorf("/pkg/ owners: got %q, want %q (mappings %v)", got, tc.want, mappings)
+			}
+		})
+	}
+}
+
+func TestScanFS_MetadataMerge(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"package.json":     {Data: []byte(`{"codeowners": "@root"}`)},
+		"web/.codeowner":   {Data: []byte("@web\n")},
+		"web/package.json": {Data: []byte(`{"codeowners": ["@frontend", "@web"]}`)},
+		"web/index.ts":     {Data: []byte("// CodeOwner: @ts\n")},
+	}
+	readers, err := scanning.BuiltinMetadataReaders("package.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want string
+	for _, concurrency := range []int{1, 4} {
+		opts := scanning.Options{
+			Prefix:          scanning.DefaultPrefix,
+			DirOwnerFile:    scanning.CodeOwnerFile,
+			Concurrency:     concurrency,
+			MetadataReaders: readers,
+		}
+		mappings, err := scanning.ScanFS(context.Background(), fsys, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := fmt.Sprint(mappings)
+		if concurrency == 1 {
+			want = got
+			for _, w := range []string{"{/ [@root]", "{/web/ [@web @frontend]", "{/web/index.ts [@ts]"} {
+				if !strings.Contains(got, w) {
+					t.Errorf("mappings %s: missing %s", got, w)
+				}
+			}
+			if strings.Count(got, "{/web/ ") != 1 {
+				t.Errorf("mappings %s: want one /web/ mapping", got)
+			}
+		} else if got != want {
+			t.Errorf("concurrency %d: got %s, want %s", concurrency, got, want)
+		}
+	}
+}
+
+func TestScanFS_MetadataErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := scanning.BuiltinMetadataReaders("setup.py"); err == nil || !strings.Contains(err.Error(), "package.json, Cargo.toml") {
+		t.Errorf("expected an error listing the supported files, got %v", err)
+	}
+
+	readers, err := scanning.BuiltinMetadataReaders("package.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Unparsable manifests are skipped with a warning, in walk order.
+	fsys := fstest.MapFS{
+		"node_modules/x/package.json": {Data: []byte(`{"name": `)},
+		"web/package.json":            {Data: []byte(`{"codeowners": 1}`)},
+		"api/package.json":            {Data: []byte(`{"codeowners": "@api"}`)},
+		"api/index.ts":                {Data: []byte("// CodeOwner: @ts\n")},
+	}
+	for _, concurrency := range []int{1, 4} {
+		var warnings []string
+		opts := scanning.Options{
+			Prefix:          scanning.DefaultPrefix,
+			Concurrency:     concurrency,
+			MetadataReaders: readers,
+			Warn:            func(err error) { warnings = append(warnings, err.Error()) },
+		}
+		mappings, err := scanning.ScanFS(context.Background(), fsys, opts)
+		if err != nil {
+			t.Fatalf("concurrency %d: unexpected error: %v", concurrency, err)
+		}
+		if got, want := fmt.Sprint(mappings), "[{/api/index.ts [@ts] [] []} {/api/ [@api] [] []}]"; got != want {
+			t.Errorf("concurrency %d: mappings %s, want %s", concurrency, got, want)
+		}
+		if len(warnings) != 2 ||
+			!strings.HasPrefix(warnings[0], "skipping node_modules/x/package.json: ") ||
+			!strings.HasPrefix(warnings[1], "skipping web/package.json: codeowners must be") {
+			t.Errorf("concurrency %d: warnings %q", concurrency, warnings)
+		}
+	}
+}
+
+func TestBackstage_OwnerTemplate(t *testing.T) {
+	t.Parallel()
+
+	b := scanning.Backstage{Owner: "@org/{owner}"}
+	if err := b.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := b.Owners([]byte("kind: Component\nspec:\n  owner: group:payments\n---\nspec:\n  owner: user:default/jane\n"), scanning.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "[@org/payments @jane]"; fmt.Sprint(got) != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+
+	if err := (scanning.Backstage{Owner: "org/{owner}"}).Validate(); err == nil || !strings.Contains(err.Error(), "must start with @") {
+		t.Errorf("expected an error for an owner without @, got %v", err)
+	}
+}
@@ -3,16 +3,19 @@ package scanning
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
-// maxFileSize is the maximum file size (1 MB) that ParseDir will scan.
+// maxFileSize is the maximum file size (1 MB) that ScanFS will scan.
 // Files larger than this are skipped to avoid wasting time on binaries or
 // generated artifacts.
 const maxFileSize = 1 << 20
@@ -24,14 +27,71 @@ const binarySniffSize = 512
 type Mapping struct {
 	Path   string
 	Owners []string
+	// Comments are the rationales given after "--" in the annotations, in
+	// file order.
+	Comments []string
+	// Prefixes are the annotation prefixes the owners were found with, in
+	// file order. They are only recorded when scanning for ExtraPrefixes,
+	// and directory owner files have none.
+	Prefixes []string
 }
 
+// commentSeparator separates an annotation's owners from its rationale.
+const commentSeparator = "--"
+
 // DefaultPrefix is the default annotation prefix to search for.
 const DefaultPrefix = "CodeOwner:"
 
 // CodeOwnerFile is the name of the directory-level ownership file.
 const CodeOwnerFile = ".codeowner"
 
+// Options configures a directory scan.
+type Options struct {
+	// Prefix is the annotation prefix to search for.
+	Prefix string
+	// ExtraPrefixes are searched for in addition to Prefix, e.g. legacy
+	// conventions in parts of a repository.
+	ExtraPrefixes []string
+	// Patterns are regular expressions matching annotations in addition to
+	// the prefixes.
+	Patterns []Pattern
+	// LanguageAnnotations also reads owners from language idioms: "Owner:"
+	// lines in Go package doc comments and Python module docstrings, and
+	// Python __owner__ variables.
+	LanguageAnnotations bool
+	// Warn, if set, is called with problems that do not stop the scan, such
+	// as a package manifest that cannot be parsed, in walk order.
+	Warn func(err error)
+	// MetadataReaders turn the owners declared in package manifests, such
+	// as package.json, into rules for the directories holding them.
+	MetadataReaders []MetadataReader
+	// DirOwnerFile is the filename for directory-level ownership.
+	DirOwnerFile string
+	// HeaderLines restricts annotation scanning to each file's leading
+	// comment block, reading at most this many lines. Zero scans whole files.
+	HeaderLines int
+	// FollowSymlinks makes the scan follow symbolic links that resolve to a
+	// location inside the root. Rules are emitted for the link path.
+	FollowSymlinks bool
+	// Filter, when set, is called with the slash-separated path of every
+	// file and directory relative to the root. Returning false skips the
+	// file, or the whole directory.
+	Filter func(path string, isDir bool) bool
+	// Concurrency is the number of files parsed in parallel. Values below
+	// two parse files one at a time while walking.
+	Concurrency int
+	// Cache, when set, is consulted before reading a file and updated with
+	// the owners found in it. The caller saves it after the scan.
+	Cache *Cache
+	// Aliases are expanded in the owners of every mapping found.
+	Aliases Aliases
+}
+
+// Prefixes returns Prefix followed by ExtraPrefixes.
+func (o Options) Prefixes() []string {
+	return append([]string{o.Prefix}, o.ExtraPrefixes...)
+}
+
 // ParseProtect parses a whitespace-separated string of owner handles and
 // returns a Mapping that protects the CODEOWNERS file itself. Each token must
 // start with @ and contain only valid characters.
@@ -42,54 +102,181 @@ func ParseProtect(s string) (Mapping, error) {
 	}
 	owners := make([]string, 0, len(fields))
 	for _, tok := range fields {
-		if !strings.HasPrefix(tok, "@") {
-			return Mapping{}, fmt.Errorf("invalid owner %q: must start with @", tok)
-		}
-		if !isValidOwner(tok) {
-			return Mapping{}, fmt.Errorf("invalid owner %q: contains invalid characters", tok)
+		if err := ValidateOwner(tok); err != nil {
+			return Mapping{}, err
 		}
 		owners = append(owners, tok)
 	}
 	return Mapping{Path: "CODEOWNERS", Owners: owners}, nil
 }
 
+// ValidateOwner checks that s is an owner handle: it must start with @ and
+// contain only valid characters.
+func ValidateOwner(s string) error {
+	if !strings.HasPrefix(s, "@") {
+		return fmt.Errorf("invalid owner %q: must start with @", s)
+	}
+	if !isValidOwner(s) {
+		return fmt.Errorf("invalid owner %q: contains invalid characters", s)
+	}
+	return nil
+}
+
 // ParseFile reads a file and returns all code owners found in annotations
 // matching the given prefix. Owners can appear on one line
 // (CodeOwner: @a @b) or across multiple lines.
 func ParseFile(path, prefix string) ([]string, error) {
-	f, err := os.Open(path)
+	return ParseFileFS(os.DirFS(filepath.Dir(path)), filepath.Base(path), prefix)
+}
+
+// ParseFileFS is like ParseFile but reads the named file from fsys.
+func ParseFileFS(fsys fs.FS, name, prefix string) ([]string, error) {
+	f, err := fsys.Open(name)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	return scanOwners(f, path, prefix)
+	found, err := scanOwners(f, name, Options{Prefix: prefix})
+	return found.owners, err
+}
+
+// annotations is what a scan finds in one file: the owners of the file, and
+// those its directory directives give its directory.
+type annotations struct {
+	owners      []string
+	comments    []string
+	prefixes    []string
+	dirOwners   []string
+	dirComments []string
+	dirPrefixes []string
 }
 
 // scanOwners extracts code owners from a reader, used by both ParseFile and
 // parseEntry (which passes an already-open file to avoid a double open).
-func scanOwners(r io.Reader, path, prefix string) ([]string, error) {
-	seen := make(map[string]struct{})
-	var owners []string
-
+// When opts.HeaderLines is set, scanning stops at the end of the file header.
+func scanOwners(r io.Reader, name string, opts Options) (annotations, error) {
+	c := newCollector()
+	prefixes := opts.Prefixes()
+	var lang languageExtractor
+	if opts.LanguageAnnotations {
+		lang = newLanguageExtractor(name)
+	}
+	header := headerState{maxLines: opts.HeaderLines}
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		for _, o := range extractOwners(scanner.Text(), prefix) {
-			owners = appendUnique(seen, owners, o)
+		line := scanner.Text()
+		if opts.HeaderLines > 0 && !header.inHeader(line) {
+			// The first code line ends the header, but may still complete a
+			// language idiom, such as the package clause after a Go
+			// package doc.
+			c.addLanguage(lang, line)
+			break
 		}
+		if owners, comment, prefix, isDir := prefixAnnotation(line, prefixes); prefix != "" {
+			c.add(owners, comment, isDir)
+			if len(owners) > 0 && len(prefixes) > 1 {
+				c.addPrefix(prefix, isDir)
+			}
+		}
+		for _, p := range opts.Patterns {
+			c.add(p.extract(line), "", false)
+		}
+		c.addLanguage(lang, line)
 	}
 	if err := scanner.Err(); err != nil {
-		return owners, fmt.Errorf("reading %s: %w", path, err)
+		return c.found, fmt.Errorf("reading %s: %w", name, err)
 	}
 
-	return owners, nil
+	return c.found, nil
+}
+
+// prefixAnnotation returns the owners and rationale of the annotation or
+// directory directive in line, the prefix it was found with, and whether it
+// is a directive. The prefix is "" if line has neither.
+func prefixAnnotation(line string, prefixes []string) (owners []string, comment, prefix string, isDir bool) {
+	for _, p := range prefixes {
+		if owners, comment := extractOwners(line, DirDirective(p)); len(owners) > 0 || comment != "" {
+			return owners, comment, p, true
+		}
+		if owners, comment := extractOwners(line, p); len(owners) > 0 || comment != "" {
+			return owners, comment, p, false
+		}
+	}
+	return nil, "", "", false
+}
+
+// collector gathers the annotations of one file, skipping duplicates.
+type collector struct {
+	found                                     annotations
+	seen, seenComments, seenPrefixes          map[string]struct{}
+	seenDir, seenDirComments, seenDirPrefixes map[string]struct{}
+}
+
+func newCollector() *collector {
+	return &collector{
+		seen:            make(map[string]struct{}),
+		seenComments:    make(map[string]struct{}),
+		seenPrefixes:    make(map[string]struct{}),
+		seenDir:         make(map[string]struct{}),
+		seenDirComments: make(map[string]struct{}),
+		seenDirPrefixes: make(map[string]struct{}),
+	}
+}
+
+// add records owners and a rationale, which may be empty, for the file, or
+// for its directory if isDir is set.
+func (c *collector) add(owners []string, comment string, isDir bool) {
+	if isDir {
+		for _, o := range owners {
+			c.found.dirOwners = appendUnique(c.seenDir, c.found.dirOwners, o)
+		}
+		if comment != "" {
+			c.found.dirComments = appendUnique(c.seenDirComments, c.found.dirComments, comment)
+		}
+		return
+	}
+	for _, o := range owners {
+		c.found.owners = appendUnique(c.seen, c.found.owners, o)
+	}
+	if comment != "" {
+		c.found.comments = appendUnique(c.seenComments, c.found.comments, comment)
+	}
+}
+
+// addPrefix records the prefix an annotation was found with, for the file,
+// or for its directory if isDir is set.
+func (c *collector) addPrefix(prefix string, isDir bool) {
+	if isDir {
+		c.found.dirPrefixes = appendUnique(c.seenDirPrefixes, c.found.dirPrefixes, prefix)
+	} else {
+		c.found.prefixes = appendUnique(c.seenPrefixes, c.found.prefixes, prefix)
+	}
+}
+
+// addLanguage feeds line to lang, if any, and records the annotations it
+// completes.
+func (c *collector) addLanguage(lang languageExtractor, line string) {
+	if lang == nil {
+		return
+	}
+	for _, a := range lang.line(line) {
+		owners, comment := extractOwners(a, docOwnerKey)
+		c.add(owners, comment, false)
+	}
 }
 
 // ParseCodeOwnerFile reads a .codeowner file and returns valid owner handles.
 // Each line is split into whitespace-separated tokens; tokens must start with @
 // and pass validation. Duplicate owners are removed.
 func ParseCodeOwnerFile(path string) ([]string, error) {
-	f, err := os.Open(path)
+	return ParseCodeOwnerFileFS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// ParseCodeOwnerFileFS is like ParseCodeOwnerFile but reads the named file
+// from fsys.
+func ParseCodeOwnerFileFS(fsys fs.FS, name string) ([]string, error) {
+	f, err := fsys.Open(name)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +294,7 @@ func ParseCodeOwnerFile(path string) ([]string, error) {
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return owners, fmt.Errorf("reading %s: %w", path, err)
+		return owners, fmt.Errorf("reading %s: %w", name, err)
 	}
 
 	return owners, nil
@@ -115,33 +302,81 @@ func ParseCodeOwnerFile(path string) ([]string, error) {
 
 // ParseDir walks a directory and returns all CodeOwner mappings.
 func ParseDir(root, prefix, dirOwnerFile string) ([]Mapping, error) {
-	var mappings []Mapping
+	return Scan(root, Options{Prefix: prefix, DirOwnerFile: dirOwnerFile})
+}
 
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.Type()&fs.ModeSymlink != 0 {
-			return nil
-		}
-		if d.IsDir() && d.Name() == ".git" {
-			return filepath.SkipDir
-		}
-		if d.IsDir() {
-			return nil
+// Scan walks a directory and returns all CodeOwner mappings, reading files
+// as configured by opts.
+func Scan(root string, opts Options) ([]Mapping, error) {
+	return ScanContext(context.Background(), root, opts)
+}
+
+// ScanContext is like Scan but stops early with the context's error once ctx
+// is done.
+func ScanContext(ctx context.Context, root string, opts Options) ([]Mapping, error) {
+	fsys, err := newDirFS(root)
+	if err != nil {
+		return nil, err
+	}
+	return ScanFS(ctx, fsys, opts)
+}
+
+// ScanFS walks fsys from its root and returns all CodeOwner mappings. Mapping
+// paths are relative to the root of fsys. Symlinks are only followed if fsys
+// implements fs.ReadLinkFS.
+func ScanFS(ctx context.Context, fsys fs.FS, opts Options) ([]Mapping, error) {
+	if err := opts.Aliases.Validate(); err != nil {
+		return nil, err
+	}
+	// Compile a copy so that patterns built by hand work too, without
+	// writing to the caller's slice.
+	opts.Patterns = slices.Clone(opts.Patterns)
+	for i := range opts.Patterns {
+		if err := opts.Patterns[i].Compile(); err != nil {
+			return nil, fmt.Errorf("pattern %d: %w", i+1, err)
 		}
+	}
 
-		m, ok, parseErr := parseEntry(root, path, d, prefix, dirOwnerFile)
-		if parseErr != nil {
-			return parseErr
+	w := &walker{ctx: ctx, fsys: fsys, opts: opts}
+	if err := w.walk(".", ".", nil); err != nil {
+		return nil, err
+	}
+	if err := w.runJobs(); err != nil {
+		return nil, err
+	}
+	mappings := mergeMappings(w.mappings)
+	for i := range mappings {
+		mappings[i].Owners = opts.Aliases.Expand(mappings[i].Owners)
+	}
+	return mappings, nil
+}
+
+// mergeMappings merges mappings for the same path, such as a directory with
+// both a directory owner file and a package manifest, into the first of
+// them, keeping the owners and comments of each.
+func mergeMappings(mappings []Mapping) []Mapping {
+	index := make(map[string]int, len(mappings))
+	merged := mappings[:0]
+	for _, m := range mappings {
+		i, ok := index[m.Path]
+		if !ok {
+			index[m.Path] = len(merged)
+			merged = append(merged, m)
+			continue
 		}
-		if ok {
-			mappings = append(mappings, m)
+		for _, o := range m.Owners {
+			if !slices.Contains(merged[i].Owners, o) {
+				merged[i].Owners = append(merged[i].Owners, o)
+			}
 		}
-		return nil
-	})
-
-	return mappings, err
+		merged[i].Comments = append(merged[i].Comments, m.Comments...)
+		for _, p := range m.Prefixes {
+			if !slices.Contains(merged[i].Prefixes, p) {
+				merged[i].Prefixes = append(merged[i].Prefixes, p)
+			}
+		}
+	}
+	return merged
 }
 
 // isBinary reports whether buf contains a null byte, indicating binary content.
@@ -149,105 +384,190 @@ func isBinary(buf []byte) bool {
 	return bytes.IndexByte(buf, 0) >= 0
 }
 
-// parseEntry handles a single file during directory walking, returning a
-// Mapping and true if ownership was found. It uses the DirEntry from WalkDir
-// to avoid a redundant os.Stat call, and opens the file once for both binary
+// parseEntry handles a single file during directory walking, returning the
+// Mappings for logical, the slash path the file was reached by: one for its
+// directory if it has directory directives, and one for the file if it has
+// annotations. The file itself is read from name, which differs from
+// logical only inside followed symlinks. It uses the DirEntry from WalkDir to
+// avoid a redundant stat call, and opens the file once for both binary
 // detection and annotation scanning.
-func parseEntry(root, path string, d fs.DirEntry, prefix, dirOwnerFile string) (Mapping, bool, error) {
-	if d.Name() == dirOwnerFile {
-		return parseDirOwnerEntry(root, path)
+func parseEntry(fsys fs.FS, name, logical string, d fs.DirEntry, opts Options) ([]Mapping, error) {
+	if d.Name() == opts.DirOwnerFile {
+		m, ok, err := parseDirOwnerEntry(fsys, name, logical)
+		if !ok {
+			return nil, err
+		}
+		return []Mapping{m}, nil
 	}
 
 	info, err := d.Info()
 	if err != nil {
-		return Mapping{}, false, err
+		return nil, err
 	}
 	if info.Size() > maxFileSize {
-		return Mapping{}, false, nil
+		return nil, nil
 	}
 
-	f, err := os.Open(path)
+	var found annotations
+	if opts.Cache != nil {
+		found, err = cachedOwners(fsys, name, info, opts)
+	} else {
+		found, err = readOwners(fsys, name, opts)
+	}
 	if err != nil {
-		return Mapping{}, false, err
+		return nil, err
+	}
+
+	var mappings []Mapping
+	if len(found.dirOwners) > 0 {
+		mappings = append(mappings, Mapping{Path: dirPath(logical), Owners: found.dirOwners, Comments: found.dirComments, Prefixes: found.dirPrefixes})
+	}
+	if len(found.owners) > 0 {
+		mappings = append(mappings, Mapping{Path: "/" + logical, Owners: found.owners, Comments: found.comments, Prefixes: found.prefixes})
+	}
+	return mappings, nil
+}
+
+// readOwners opens the named file and scans it for owners, skipping binary
+// files.
+func readOwners(fsys fs.FS, name string, opts Options) (annotations, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return annotations{}, err
 	}
 	defer f.Close()
 
-	// Sniff the first bytes to detect binary content.
-	buf := make([]byte, binarySniffSize)
-	n, err := f.Read(buf)
+	// Sniff the first bytes to detect binary content. Peeking leaves them in
+	// the buffer so scanOwners still reads the full file.
+	br := bufio.NewReader(f)
+	buf, err := br.Peek(binarySniffSize)
 	if err != nil && !errors.Is(err, io.EOF) {
-		return Mapping{}, false, err
+		return annotations{}, err
 	}
-	if isBinary(buf[:n]) {
-		return Mapping{}, false, nil
+	if isBinary(buf) {
+		return annotations{}, nil
 	}
 
-	// Seek back to the start so scanOwners reads the full file.
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return Mapping{}, false, err
+	return scanOwners(br, name, opts)
+}
+
+// cachedOwners is readOwners backed by opts.Cache. Unchanged files are not
+// read at all; changed files are read once to hash and scan them.
+func cachedOwners(fsys fs.FS, name string, info fs.FileInfo, opts Options) (annotations, error) {
+	if found, ok := opts.Cache.lookupStat(name, info); ok {
+		return found, nil
 	}
 
-	owners, err := scanOwners(f, path, prefix)
+	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
-		return Mapping{}, false, err
+		return annotations{}, err
 	}
-	if len(owners) == 0 {
-		return Mapping{}, false, nil
+	hash := hashContent(data)
+	if found, ok := opts.Cache.lookupHash(name, info, hash); ok {
+		return found, nil
 	}
-	rel, relErr := filepath.Rel(root, path)
-	if relErr != nil {
-		rel = path
+
+	var found annotations
+	if !isBinary(data[:min(len(data), binarySniffSize)]) {
+		found, err = scanOwners(bytes.NewReader(data), name, opts)
+		if err != nil {
+			return annotations{}, err
+		}
 	}
-	return Mapping{Path: "/" + filepath.ToSlash(rel), Owners: owners}, true, nil
+	opts.Cache.store(name, info, hash, found)
+	return found, nil
 }
 
 // parseDirOwnerEntry handles a .codeowner file, returning a directory-level
 // Mapping with a root-anchored trailing-slash path.
-func parseDirOwnerEntry(root, path string) (Mapping, bool, error) {
-	owners, err := ParseCodeOwnerFile(path)
+func parseDirOwnerEntry(fsys fs.FS, name, logical string) (Mapping, bool, error) {
+	owners, err := ParseCodeOwnerFileFS(fsys, name)
 	if err != nil {
 		return Mapping{}, false, err
 	}
 	if len(owners) == 0 {
 		return Mapping{}, false, nil
 	}
-	rel, relErr := filepath.Rel(root, filepath.Dir(path))
-	if relErr != nil {
-		rel = filepath.Dir(path)
+	return Mapping{Path: dirPath(logical), Owners: owners}, true, nil
+}
+
+// dirPath returns the directory-level Mapping path for the directory holding
+// the file logical: "/" for the root, or "/dir/".
+func dirPath(logical string) string {
+	dir := path.Dir(logical)
+	if dir == "." {
+		return "/"
 	}
-	if rel == "." {
-		return Mapping{Path: "/", Owners: owners}, true, nil
+	return "/" + dir + "/"
+}
+
+// DirDirective returns the form of prefix that gives owners to the
+// directory of the annotated file rather than to the file itself:
+// "CodeOwner(dir):" for "CodeOwner:".
+func DirDirective(prefix string) string {
+	if p, ok := strings.CutSuffix(prefix, ":"); ok {
+		return p + "(dir):"
 	}
-	return Mapping{Path: "/" + filepath.ToSlash(rel) + "/", Owners: owners}, true, nil
+	return prefix + "(dir)"
 }
 
-// extractOwners parses all @-prefixed tokens after the prefix on a line.
-func extractOwners(line, prefix string) []string {
+// AnnotationStart returns the offset in line just past an annotation's
+// prefix, where its owners start, or -1 if line has no annotation.
+func AnnotationStart(line, prefix string) int {
 	idx := strings.Index(line, prefix)
 	if idx < 0 {
-		return nil
+		return -1
 	}
 
 	// The prefix must be at the start of the line or preceded by whitespace.
 	if idx > 0 && line[idx-1] != ' ' && line[idx-1] != '\t' {
-		return nil
+		return -1
 	}
 
-	rest := line[idx+len(prefix):]
-
 	// Require a space between the prefix and the owners.
-	if rest == "" || rest[0] != ' ' {
-		return nil
+	end := idx + len(prefix)
+	if end == len(line) || line[end] != ' ' {
+		return -1
+	}
+	return end
+}
+
+// extractOwners parses all @-prefixed tokens after the prefix on a line,
+// up to a "--" token, and returns the rationale that follows it.
+func extractOwners(line, prefix string) ([]string, string) {
+	start := AnnotationStart(line, prefix)
+	if start < 0 {
+		return nil, ""
 	}
+	rest := line[start:]
 
 	var owners []string
 	for _, token := range strings.Fields(rest) {
+		if token == commentSeparator {
+			return owners, extractComment(rest)
+		}
 		if strings.HasPrefix(token, "@") && isValidOwner(token) {
 			owners = append(owners, token)
 		}
 	}
 
-	return owners
+	return owners, ""
+}
+
+// commentClosers end block comments that may follow an annotation's
+// rationale on the same line.
+var commentClosers = []string{"*/", "-->", "*)"}
+
+// extractComment returns the text after the first "--" token in rest,
+// without a trailing block comment closer.
+func extractComment(rest string) string {
+	padded := " " + strings.ReplaceAll(rest, "\t", " ") + " "
+	_, comment, _ := strings.Cut(padded, " "+commentSeparator+" ")
+	comment = strings.TrimSpace(comment)
+	for _, c := range commentClosers {
+		comment = strings.TrimSpace(strings.TrimSuffix(comment, c))
+	}
+	return comment
 }
 
 // appendUnique appends token to owners if it has not been seen before.
@@ -1,11 +1,15 @@
 package scanning_test
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/kevin-robayna/codeowner/internal/scanning"
 )
@@ -875,3 +879,339 @@ func TestParseFile_RejectsNoAt(t *testing.T) {
 		t.Errorf("should reject owner without @ prefix, got %v", got)
 	}
 }
+
+func TestScan_HeaderLines(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "annotation in leading comment",
+			content: "// CodeOwner: @header-team\npackage main\n",
+			want:    []string{"@header-team"},
+		},
+		{
+			name:    "annotation after code is ignored",
+			content: "// License header\npackage main\n\n// CodeOwner: @late-team\n",
+		},
+		{
+			name:    "annotation after block comment and blank lines",
+			content: "/*\nLicense text\n*/\n// CodeOwner: @block-team\npackage main\n",
+			want:    []string{"@block-team"},
+		},
+		{
+			name:    "annotation inside block comment",
+			content: "<!--\n  CodeOwner: @html-team\n-->\n<html></html>\n",
+			want:    []string{"@html-team"},
+		},
+		{
+			name:    "annotation after php open tag",
+			content: "<?php\n// CodeOwner: @php-team\n$x = 1;\n",
+			want:    []string{"@php-team"},
+		},
+		{
+			name:    "annotation beyond line limit is ignored",
+			content: "#!/bin/sh\n#\n#\n#\n# CodeOwner: @deep-team\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "file"), []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			mappings, err := scanning.Scan(dir, scanning.Options{
+				Prefix:       scanning.DefaultPrefix,
+				DirOwnerFile: scanning.CodeOwnerFile,
+				HeaderLines:  4,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			if len(mappings) > 0 {
+				got = mappings[0].Owners
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("owners = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScan_HeaderMatchesFullScanOnTestdata(t *testing.T) {
+	t.Parallel()
+
+	dir := testdataDir()
+
+	full, err := scanning.ParseDir(dir, scanning.DefaultPrefix, scanning.CodeOwnerFile)
+	if err != nil {
+		t.Fatalf("ParseDir error: %v", err)
+	}
+	header, err := scanning.Scan(dir, scanning.Options{
+		Prefix:       scanning.DefaultPrefix,
+		DirOwnerFile: scanning.CodeOwnerFile,
+		HeaderLines:  scanning.DefaultHeaderLines,
+	})
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+
+	if !slices.EqualFunc(full, header, func(a, b scanning.Mapping) bool {
+		return a.Path == b.Path && slices.Equal(a.Owners, b.Owners)
+	}) {
+		t.Errorf("header scan differs from full scan:\nfull:   %v\nheader: %v", full, header)
+	}
+}
+
+func BenchmarkScan_Full(b *testing.B) {
+	benchmarkScan(b, 0)
+}
+
+func BenchmarkScan_Header(b *testing.B) {
+	benchmarkScan(b, scanning.DefaultHeaderLines)
+}
+
+func benchmarkScan(b *testing.B, headerLines int) {
+	b.Helper()
+
+	opts := scanning.Options{
+		Prefix:       scanning.DefaultPrefix,
+		DirOwnerFile: scanning.CodeOwnerFile,
+		HeaderLines:  headerLines,
+	}
+	dir := testdataDir()
+	for b.Loop() {
+		if _, err := scanning.Scan(dir, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestScan_FollowSymlinks(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := func(target, path string) {
+		t.Helper()
+		if err := os.Symlink(target, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(filepath.Join(root, "shared", "config.yaml"), "# CodeOwner: @config-team\n")
+	write(filepath.Join(root, "shared", ".codeowner"), "@shared-team\n")
+	write(filepath.Join(root, "main.go"), "// CodeOwner: @main-team\n")
+	write(filepath.Join(outside, "secret.go"), "// CodeOwner: @outside-team\n")
+
+	if err := os.Mkdir(filepath.Join(root, "svc"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link(filepath.Join(root, "shared"), filepath.Join(root, "svc", "config"))
+	link(filepath.Join("..", "main.go"), filepath.Join(root, "svc", "main.go"))
+	link(outside, filepath.Join(root, "outside"))
+	link(filepath.Join(root, "missing"), filepath.Join(root, "dangling"))
+	// A link to an ancestor and a pair of links pointing at each other.
+	link(root, filepath.Join(root, "shared", "loop"))
+	link(filepath.Join(root, "svc"), filepath.Join(root, "shared", "svc"))
+
+	mappings, err := scanning.Scan(root, scanning.Options{
+		Prefix:         scanning.DefaultPrefix,
+		DirOwnerFile:   scanning.CodeOwnerFile,
+		FollowSymlinks: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := make(map[string][]string)
+	for _, m := range mappings {
+		found[m.Path] = m.Owners
+	}
+
+	checks := map[string][]string{
+		"/main.go":                {"@main-team"},
+		"/shared/":                {"@shared-team"},
+		"/shared/config.yaml":     {"@config-team"},
+		"/svc/main.go":            {"@main-team"},
+		"/svc/config/":            {"@shared-team"},
+		"/svc/config/config.yaml": {"@config-team"},
+	}
+	for path, want := range checks {
+		if got, ok := found[path]; !ok {
+			t.Errorf("missing mapping for %s", path)
+		} else if !slices.Equal(got, want) {
+			t.Errorf("mapping for %s = %v, want %v", path, got, want)
+		}
+	}
+
+	for path := range found {
+		if strings.HasPrefix(path, "/outside/") {
+			t.Errorf("link outside the root should be skipped, got %s", path)
+		}
+		if strings.Contains(path, "/loop/") || strings.Count(path, "/svc/") > 1 {
+			t.Errorf("symlink cycle should be skipped, got %s", path)
+		}
+	}
+}
+
+func TestScan_Filter(t *testing.T) {
+	t.Parallel()
+
+	dir := testdataDir()
+
+	var visited []string
+	mappings, err := scanning.Scan(dir, scanning.Options{
+		Prefix:       scanning.DefaultPrefix,
+		DirOwnerFile: scanning.CodeOwnerFile,
+		Filter: func(path string, isDir bool) bool {
+			visited = append(visited, path)
+			return path == "nested" || strings.HasPrefix(path, "nested/") && path != "nested/deeply"
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, m := range mappings {
+		got = append(got, m.Path)
+	}
+	if want := []string{"/nested/handler.go"}; !slices.Equal(got, want) {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+	if slices.Contains(visited, "nested/deeply/service.py") {
+		t.Error("filtered directories should not be descended into")
+	}
+}
+
+func TestScan_Comments(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		content    string
+		wantOwners []string
+		wantNotes  []string
+	}{
+		{
+			name:       "rationale after owners",
+			content:    "# CodeOwner: @sre-team -- on-call rotation owns deploy scripts\n",
+			wantOwners: []string{"@sre-team"},
+			wantNotes:  []string{"on-call rotation owns deploy scripts"},
+		},
+		{
+			name:       "handles in the rationale are not owners",
+			content:    "// CodeOwner: @a @b -- ask @c before changing\n",
+			wantOwners: []string{"@a", "@b"},
+			wantNotes:  []string{"ask @c before changing"},
+		},
+		{
+			name:       "block comment closer is dropped",
+			content:    "<!-- CodeOwner: @web -- design system -->\n/* CodeOwner: @web -- shared styles */\n",
+			wantOwners: []string{"@web"},
+			wantNotes:  []string{"design system", "shared styles"},
+		},
+		{
+			name:       "one rationale per annotation, deduplicated",
+			content:    "# CodeOwner: @a -- billing\n# CodeOwner: @b\n# CodeOwner: @c -- billing\n",
+			wantOwners: []string{"@a", "@b", "@c"},
+			wantNotes:  []string{"billing"},
+		},
+		{
+			name:       "closing html comment is not a separator",
+			content:    "<!-- CodeOwner: @web -->\n",
+			wantOwners: []string{"@web"},
+		},
+		{
+			name:       "empty rationale",
+			content:    "# CodeOwner: @a --\n",
+			wantOwners: []string{"@a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "file"), []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := scanning.Options{Prefix: scanning.DefaultPrefix, DirOwnerFile: scanning.CodeOwnerFile}
+			cached := opts
+			cached.Cache = scanning.OpenCache(filepath.Join(t.TempDir(), "cache.json"), opts)
+			for _, o := range []scanning.Options{opts, cached} {
+				mappings, err := scanning.Scan(dir, o)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(mappings) != 1 {
+					t.Fatalf("got %d mappings, want 1", len(mappings))
+				}
+				if !slices.Equal(mappings[0].Owners, tc.wantOwners) {
+					t.Errorf("owners = %v, want %v", mappings[0].Owners, tc.wantOwners)
+				}
+				if !slices.Equal(mappings[0].Comments, tc.wantNotes) {
+					t.Errorf("comments = %q, want %q", mappings[0].Comments, tc.wantNotes)
+				}
+			}
+		})
+	}
+}
+
+func TestScan_DirDirective(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"doc.go":             {Data: []byte("// CodeOwner(dir): @root\npackage main\n")},
+		"billing/doc.go":     {Data: []byte("// Package billing charges customers.\n//\n// CodeOwner(dir): @payments -- billing on-call\npackage billing\n")},
+		"billing/.codeowner": {Data: []byte("@finance\n")},
+		"billing/charge.go":  {Data: []byte("// CodeOwner(dir): @payments\n// CodeOwner: @sre\npackage billing\n")},
+		"api/handler.go":     {Data: []byte("// Owner(dir): @api\npackage api\n")},
+		"web/index.ts":       {Data: []byte("// CodeOwner(dir):@nospace\n")},
+	}
+	opts := scanning.Options{Prefix: scanning.DefaultPrefix, DirOwnerFile: scanning.CodeOwnerFile, ExtraPrefixes: []string{"Owner:"}}
+
+	for _, concurrency := range []int{1, 4} {
+		opts.Concurrency = concurrency
+		mappings, err := scanning.ScanFS(context.Background(), fsys, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []scanning.Mapping{
+			{Path: "/api/", Owners: []string{"@api"}, Prefixes: []string{"Owner:"}},
+			{Path: "/billing/", Owners: []string{"@finance", "@payments"}, Comments: []string{"billing on-call"}, Prefixes: []string{"CodeOwner:"}},
+			{Path: "/billing/charge.go", Owners: []string{"@sre"}, Prefixes: []string{"CodeOwner:"}},
+			{Path: "/", Owners: []string{"@root"}, Prefixes: []string{"CodeOwner:"}},
+		}
+		if got := fmt.Sprint(mappings); got != fmt.Sprint(want) {
+			t.Errorf("concurrency %d:\ngot:  %v\nwant: %v", concurrency, got, want)
+		}
+	}
+
+	if got := scanning.DirDirective("@owner"); got != "@owner(dir)" {
+		t.Errorf("DirDirective(@owner) = %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package scanning
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// OwnersGroup is the name of the capture group holding the owners in a
+// Pattern's regular expression.
+const OwnersGroup = "owners"
+
+// ownerPlaceholder is replaced by each captured owner in Pattern.Owner.
+const ownerPlaceholder = "{owner}"
+
+// Pattern matches annotations with a regular expression, as an alternative
+// to a fixed prefix, for ownership embedded in structured headers such as
+// "@owner {team: backend}". Scans compile their patterns; Compile checks one
+// up front, e.g. when loading a configuration.
+type Pattern struct {
+	// Regex is the regular expression matching an annotation. Its owners
+	// group captures the owners, separated by whitespace or commas.
+	Regex string `yaml:"regex"`
+	// Owner turns each captured owner into a handle, with {owner} standing
+	// for the capture, e.g. "@org/{owner}". Empty uses captures starting
+	// with @ as they are and prefixes the others with @.
+	Owner string `yaml:"owner,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Compile checks the pattern and prepares it for matching. The regular
+// expression must be valid and have an owners group, and Owner, if set,
+// must contain {owner} and start with @.
+func (p *Pattern) Compile() error {
+	if p.Regex == "" {
+		return errors.New("regex is required")
+	}
+	re, err := regexp.Compile(p.Regex)
+	if err != nil {
+		return fmt.Errorf("invalid regex: %w", err)
+	}
+	if re.SubexpIndex(OwnersGroup) < 0 {
+		return fmt.Errorf("regex %q has no (?P<%s>...) group capturing the owners", p.Regex, OwnersGroup)
+	}
+	if p.Owner != "" {
+		if !strings.Contains(p.Owner, ownerPlaceholder) {
+			return fmt.Errorf("owner %q does not contain %s", p.Owner, ownerPlaceholder)
+		}
+		if err := ValidateOwner(strings.ReplaceAll(p.Owner, ownerPlaceholder, "team")); err != nil {
+			return fmt.Errorf("owner %q: %w", p.Owner, err)
+		}
+	}
+	p.re = re
+	return nil
+}
+
+// String returns the pattern's regular expression.
+func (p Pattern) String() string {
+	return p.Regex
+}
+
+// extract returns the valid owner handles captured by every match of the
+// compiled pattern in line.
+func (p Pattern) extract(line string) []string {
+	group := p.re.SubexpIndex(OwnersGroup)
+	var owners []string
+	for _, m := range p.re.FindAllStringSubmatch(line, -1) {
+		for _, tok := range strings.FieldsFunc(m[group], isOwnerSeparator) {
+			if o := p.handle(tok); isValidOwner(o) && strings.HasPrefix(o, "@") {
+				owners = append(owners, o)
+			}
+		}
+	}
+	return owners
+}
+
+// handle turns a captured owner into a handle.
+func (p Pattern) handle(tok string) string {
+	if p.Owner != "" {
+		return strings.ReplaceAll(p.Owner, ownerPlaceholder, strings.TrimPrefix(tok, "@"))
+	}
+	if strings.HasPrefix(tok, "@") {
+		return tok
+	}
+	return "@" + tok
+}
+
+func isOwnerSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t'
+}
@@ -0,0 +1,117 @@
+package scanning_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+func TestPattern_Compile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		pattern scanning.Pattern
+		wantErr string
+	}{
+		{name: "valid", pattern: scanning.Pattern{Regex: `@owner \{team: (?P<owners>[\w-]+)\}`}},
+		{name: "valid with owner", pattern: scanning.Pattern{Regex: `team=(?P<owners>\S+)`, Owner: "@org/{owner}"}},
+		{name: "missing regex", wantErr: "regex is required"},
+		{name: "invalid regex", pattern: scanning.Pattern{Regex: `(?P<owners>[a-z`}, wantErr: "invalid regex: error parsing regexp"},
+		{name: "no owners group", pattern: scanning.Pattern{Regex: `Owner: (\S+)`}, wantErr: "has no (?P<owners>...) group"},
+		{name: "owner without placeholder", pattern: scanning.Pattern{Regex: `(?P<owners>\S+)`, Owner: "@org/team"}, wantErr: "does not contain {owner}"},
+		{name: "owner without at", pattern: scanning.Pattern{Regex: `(?P<owners>\S+)`, Owner: "org/{owner}"}, wantErr: "must start with @"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.pattern.Compile()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("got error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestScan_Patterns(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		patterns []scanning.Pattern
+		content  string
+		want     []string
+	}{
+		{
+			name:     "structured header",
+			patterns: []scanning.Pattern{{Regex: `@owner \{team: (?P<owners>[\w-]+)\}`, Owner: "@org/{owner}"}},
+			content:  "/**\n * @owner {team: backend}\n */\n",
+			want:     []string{"@org/backend"},
+		},
+		{
+			name:     "spdx contributors",
+			patterns: []scanning.Pattern{{Regex: `SPDX-FileContributor: (?P<owners>@\S+)`}},
+			content:  "# SPDX-FileContributor: @alice\n# SPDX-FileContributor: @bob\n# SPDX-FileContributor: Carol <carol@example.com>\n",
+			want:     []string{"@alice", "@bob"},
+		},
+		{
+			name:     "comma separated list gets @",
+			patterns: []scanning.Pattern{{Regex: `owners: \[(?P<owners>[^\]]*)\]`}},
+			content:  "# owners: [web, @design,  bad!name]\n",
+			want:     []string{"@web", "@design"},
+		},
+		{
+			name:     "alongside the prefix",
+			patterns: []scanning.Pattern{{Regex: `team=(?P<owners>\w+)`}},
+			content:  "// CodeOwner: @a\n// team=b\n// team=a\n",
+			want:     []string{"@a", "@b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "file"), []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			mappings, err := scanning.Scan(dir, scanning.Options{
+				Prefix:       scanning.DefaultPrefix,
+				DirOwnerFile: scanning.CodeOwnerFile,
+				Patterns:     tc.patterns,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(mappings) != 1 || !slices.Equal(mappings[0].Owners, tc.want) {
+				t.Errorf("got %v, want owners %v", mappings, tc.want)
+			}
+		})
+	}
+}
+
+func TestScan_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	_, err := scanning.Scan(t.TempDir(), scanning.Options{
+		Prefix:   scanning.DefaultPrefix,
+		Patterns: []scanning.Pattern{{Regex: `Owner: (\S+)`}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "pattern 1: ") {
+		t.Errorf("expected pattern error, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package scanning
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// maxLinkHops bounds the number of symlinks resolved for a single path, so
+// that links pointing at each other cannot loop forever.
+const maxLinkHops = 255
+
+// errLinkOutside is returned by resolveLink for links leaving the filesystem.
+var errLinkOutside = errors.New("symlink target is outside the scanned root")
+
+// followSymlink scans the target of the symlink name as if it lived at
+// logicalPath. Links that dangle, resolve outside the root, or point at a
+// directory containing one of the links being followed are skipped.
+func (w *walker) followSymlink(name, logicalPath string, d fs.DirEntry, parents []string) error {
+	target, err := resolveLink(w.fsys, name)
+	if err != nil {
+		// Dangling and escaping links have nothing to scan.
+		return nil
+	}
+
+	info, err := fs.Stat(w.fsys, target)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return w.parse(target, logicalPath, linkEntry{DirEntry: d, target: info})
+	}
+
+	parent, err := resolveLink(w.fsys, path.Dir(name))
+	if err != nil {
+		return err
+	}
+	chain := append(slices.Clone(parents), parent)
+	for _, p := range chain {
+		if within(target, p) {
+			// The target contains the link itself: following it would loop.
+			return nil
+		}
+	}
+	return w.walk(target, logicalPath, chain)
+}
+
+// resolveLink returns the path inside fsys that name refers to once every
+// symlink along it is resolved. It fails for dangling links, links that leave
+// fsys through ".." or an absolute target, and chains of more than
+// maxLinkHops links.
+func resolveLink(fsys fs.FS, name string) (string, error) {
+	var resolved []string
+	pending := strings.Split(name, "/")
+	hops := 0
+
+	for len(pending) > 0 {
+		elem := pending[0]
+		pending = pending[1:]
+
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if len(resolved) == 0 {
+				return "", errLinkOutside
+			}
+			resolved = resolved[:len(resolved)-1]
+			continue
+		}
+
+		candidate := path.Join(path.Join(resolved...), elem)
+		info, err := fs.Lstat(fsys, candidate)
+		if err != nil {
+			return "", err
+		}
+		if info.Mode().Type() != fs.ModeSymlink {
+			resolved = append(resolved, elem)
+			continue
+		}
+
+		hops++
+		if hops > maxLinkHops {
+			return "", errors.New("too many levels of symbolic links")
+		}
+		target, err := fs.ReadLink(fsys, candidate)
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) {
+			return "", errLinkOutside
+		}
+		// The target is relative to the link's directory, which is resolved.
+		pending = append(strings.Split(target, "/"), pending...)
+	}
+
+	if len(resolved) == 0 {
+		return ".", nil
+	}
+	return path.Join(resolved...), nil
+}
+
+// within reports whether the slash path p is base or lies inside it.
+func within(base, p string) bool {
+	return base == "." || p == base || strings.HasPrefix(p, base+"/")
+}
+
+// linkEntry is a symlink DirEntry that reports the type and info of the file
+// it points to, while keeping the link's own name.
+type linkEntry struct {
+	fs.DirEntry
+	target fs.FileInfo
+}
+
+func (e linkEntry) IsDir() bool                { return e.target.IsDir() }
+func (e linkEntry) Type() fs.FileMode          { return e.target.Mode().Type() }
+func (e linkEntry) Info() (fs.FileInfo, error) { return e.target, nil }
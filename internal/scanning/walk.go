@@ -0,0 +1,163 @@
+package scanning
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// walker holds the state of a single scan.
+type walker struct {
+	ctx      context.Context
+	fsys     fs.FS
+	opts     Options
+	mappings []Mapping
+	jobs     []job // files queued for parallel parsing
+}
+
+// job is a file queued for parsing when scanning concurrently. Manifests
+// are queued with their metadata reader.
+type job struct {
+	name, logical string
+	d             fs.DirEntry
+	meta          MetadataReader
+}
+
+// run parses the file of the job.
+func (j job) run(fsys fs.FS, opts Options) ([]Mapping, error) {
+	if j.meta == nil {
+		return parseEntry(fsys, j.name, j.logical, j.d, opts)
+	}
+	m, ok, err := parseMetadataEntry(fsys, j.name, j.logical, j.meta, opts)
+	if !ok {
+		return nil, err
+	}
+	return []Mapping{m}, nil
+}
+
+// walk scans the directory dir, reporting every entry under the logical path
+// it was reached by. The two differ only inside followed symlinks. parents
+// holds the resolved parent directories of the symlinks followed so far.
+func (w *walker) walk(dir, logical string, parents []string) error {
+	return fs.WalkDir(w.fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+		logicalPath := rebase(name, dir, logical)
+		if !w.include(logicalPath, d) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			if !w.opts.FollowSymlinks {
+				return nil
+			}
+			return w.followSymlink(name, logicalPath, d, parents)
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		return w.parse(name, logicalPath, d)
+	})
+}
+
+// rebase moves name, a path at or below dir, to the same place below logical.
+func rebase(name, dir, logical string) string {
+	if dir == "." {
+		return path.Join(logical, name)
+	}
+	return path.Join(logical, strings.TrimPrefix(name, dir))
+}
+
+// include reports whether the Filter option keeps path. The root itself is
+// always included.
+func (w *walker) include(path string, d fs.DirEntry) bool {
+	if w.opts.Filter == nil || path == "." {
+		return true
+	}
+	return w.opts.Filter(path, d.IsDir())
+}
+
+// parse scans a single file and records its mappings, if any. A package
+// manifest only yields one for its directory: the reader already reads its
+// annotations, such as the comments of a go.mod, so a rule for the file
+// itself would repeat the directory rule. When scanning concurrently the
+// file is queued for runJobs instead.
+func (w *walker) parse(name, logical string, d fs.DirEntry) error {
+	j := job{name: name, logical: logical, d: d, meta: metadataReader(w.opts, d.Name())}
+	if w.opts.Concurrency > 1 {
+		w.jobs = append(w.jobs, j)
+		return nil
+	}
+	return w.record(j.run(w.fsys, w.opts))
+}
+
+// record adds the mappings of a parsed file, or returns the error parsing
+// it. Unparsable package manifests are reported to opts.Warn instead.
+func (w *walker) record(mappings []Mapping, err error) error {
+	var me *metadataError
+	if errors.As(err, &me) {
+		if w.opts.Warn != nil {
+			w.opts.Warn(err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	w.mappings = append(w.mappings, mappings...)
+	return nil
+}
+
+// runJobs parses the queued files with opts.Concurrency workers. Mappings
+// keep walk order, and the error of the earliest failing file is returned.
+func (w *walker) runJobs() error {
+	if len(w.jobs) == 0 {
+		return nil
+	}
+
+	type result struct {
+		m   []Mapping
+		err error
+	}
+	results := make([]result, len(w.jobs))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(w.opts.Concurrency, len(w.jobs)) {
+		wg.Go(func() {
+			for i := range next {
+				if err := w.ctx.Err(); err != nil {
+					results[i].err = err
+					continue
+				}
+				m, err := w.jobs[i].run(w.fsys, w.opts)
+				results[i] = result{m: m, err: err}
+			}
+		})
+	}
+	for i := range w.jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, r := range results {
+		if err := w.record(r.m, r.err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// Package suggest picks reviewers for a changeset: the smallest set of
+// owners that together own every changed file.
+package suggest
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+)
+
+// maxSearchNodes bounds the exact search in cover. Beyond it the greedy
+// answer is used, which is close to minimal in practice.
+const maxSearchNodes = 100000
+
+// Reviewer is a suggested owner and the changed files they own.
+type Reviewer struct {
+	Owner string   `json:"owner"`
+	Files []string `json:"files"`
+}
+
+// Result is the reviewer suggestion for a changeset.
+type Result struct {
+	Reviewers []Reviewer `json:"reviewers"`
+	// Unowned lists changed files no rule assigns an owner to. No reviewer
+	// can cover them.
+	Unowned []string `json:"unowned"`
+}
+
+// Reviewers returns a minimal set of owners that covers every changed file
+// under rules, with the changed files each of them owns. Files are
+// slash-separated paths relative to the repository root.
+func Reviewers(rules matcher.Ruleset, files []string) Result {
+	res := Result{Reviewers: []Reviewer{}, Unowned: []string{}}
+
+	// Files with the same owners need the same reviewers, so the cover is
+	// computed over distinct owner sets.
+	owned := make(map[string][]string) // owner -> files
+	groups := make(map[string][]string)
+	for _, f := range normalize(files) {
+		owners := rules.Owners(f)
+		if len(owners) == 0 {
+			res.Unowned = append(res.Unowned, "/"+f)
+			continue
+		}
+		for _, o := range owners {
+			owned[o] = append(owned[o], "/"+f)
+		}
+		groups[strings.Join(owners, " ")] = owners
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	sets := make([][]string, 0, len(groups))
+	for _, k := range keys {
+		sets = append(sets, groups[k])
+	}
+	for _, owner := range cover(sets) {
+		res.Reviewers = append(res.Reviewers, Reviewer{Owner: owner, Files: owned[owner]})
+	}
+	return res
+}
+
+// normalize cleans file paths, dropping blanks and duplicates.
+func normalize(files []string) []string {
+	out := make([]string, 0, len(files))
+	for _, f := range files {
+		f = strings.TrimPrefix(strings.TrimSpace(f), "./")
+		f = strings.TrimPrefix(f, "/")
+		if f != "" {
+			out = append(out, f)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// cover returns a smallest set of owners containing at least one owner of
+// every set, sorted. It searches exactly, falling back to a greedy answer
+// for very large inputs.
+func cover(sets [][]string) []string {
+	best := greedy(sets)
+	s := search{sets: sets, best: best}
+	s.run(nil)
+	slices.Sort(s.best)
+	return s.best
+}
+
+// greedy repeatedly picks the owner covering the most uncovered sets.
+func greedy(sets [][]string) []string {
+	var picked []string
+	remaining := sets
+	for len(remaining) > 0 {
+		count := make(map[string]int)
+		for _, set := range remaining {
+			for _, o := range set {
+				count[o]++
+			}
+		}
+		owners := make([]string, 0, len(count))
+		for o := range count {
+			owners = append(owners, o)
+		}
+		sort.Slice(owners, func(i, j int) bool {
+			if count[owners[i]] != count[owners[j]] {
+				return count[owners[i]] > count[owners[j]]
+			}
+			return owners[i] < owners[j]
+		})
+		picked = append(picked, owners[0])
+		remaining = uncovered(remaining, picked)
+	}
+	return picked
+}
+
+// search is a branch-and-bound search for a minimum cover.
+type search struct {
+	sets  [][]string
+	best  []string
+	nodes int
+}
+
+func (s *search) run(picked []string) {
+	s.nodes++
+	if s.nodes > maxSearchNodes || len(picked) >= len(s.best) {
+		return
+	}
+	remaining := uncovered(s.sets, picked)
+	if len(remaining) == 0 {
+		s.best = slices.Clone(picked)
+		return
+	}
+	if len(picked)+1 >= len(s.best) {
+		// Covering what is left needs at least one more owner.
+		return
+	}
+
+	// Branch on the uncovered set with the fewest owners: one of them must
+	// be picked.
+	next := slices.MinFunc(remaining, func(a, b []string) int { return len(a) - len(b) })
+	for _, o := range next {
+		s.run(append(slices.Clone(picked), o))
+	}
+}
+
+// uncovered returns the sets without any owner in picked.
+func uncovered(sets [][]string, picked []string) [][]string {
+	var out [][]string
+	for _, set := range sets {
+		if !slices.ContainsFunc(set, func(o string) bool { return slices.Contains(picked, o) }) {
+			out = append(out, set)
+		}
+	}
+	return out
+}
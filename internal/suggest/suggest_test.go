@@ -0,0 +1,76 @@
+package suggest_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kevin-robayna/codeowner/internal/matcher"
+	"github.com/kevin-robayna/codeowner/internal/suggest"
+)
+
+func TestReviewers(t *testing.T) {
+	t.Parallel()
+
+	rules := matcher.ParseString("/api/ @backend @platform\n" +
+		"/api/billing.go @payments @backend\n" +
+		"/web/ @frontend @platform\n" +
+		"/docs/ @docs\n" +
+		"/vendor/\n")
+
+	got := suggest.Reviewers(rules, []string{
+		"api/handler.go",
+		"./api/billing.go",
+		"web/index.html",
+		"docs/guide.md",
+		"vendor/lib.go",
+		"api/handler.go",
+		"",
+	})
+
+	// Every cover needs @docs plus two owners for api/ and web/; @backend
+	// covers both api/ files, so @frontend completes the cover.
+	want := []suggest.Reviewer{
+		{Owner: "@backend", Files: []string{"/api/billing.go", "/api/handler.go"}},
+		{Owner: "@docs", Files: []string{"/docs/guide.md"}},
+		{Owner: "@frontend", Files: []string{"/web/index.html"}},
+	}
+	if !slices.EqualFunc(got.Reviewers, want, func(a, b suggest.Reviewer) bool {
+		return a.Owner == b.Owner && slices.Equal(a.Files, b.Files)
+	}) {
+		t.Errorf("Reviewers:\ngot:  %v\nwant: %v", got.Reviewers, want)
+	}
+	if want := []string{"/vendor/lib.go"}; !slices.Equal(got.Unowned, want) {
+		t.Errorf("Unowned = %v, want %v", got.Unowned, want)
+	}
+}
+
+func TestReviewers_BeatsGreedy(t *testing.T) {
+	t.Parallel()
+
+	// Greedy picks @wide first (it covers 3 sets) and then needs two more
+	// owners; @left and @right alone cover everything.
+	rules := matcher.ParseString("/a @left @wide\n" +
+		"/b @left @wide\n" +
+		"/c @right @wide\n" +
+		"/d @left\n" +
+		"/e @right\n")
+
+	got := suggest.Reviewers(rules, []string{"a", "b", "c", "d", "e"})
+
+	var owners []string
+	for _, r := range got.Reviewers {
+		owners = append(owners, r.Owner)
+	}
+	if want := []string{"@left", "@right"}; !slices.Equal(owners, want) {
+		t.Errorf("owners = %v, want %v", owners, want)
+	}
+}
+
+func TestReviewers_NoFiles(t *testing.T) {
+	t.Parallel()
+
+	got := suggest.Reviewers(matcher.ParseString("* @everyone\n"), nil)
+	if len(got.Reviewers) != 0 || len(got.Unowned) != 0 {
+		t.Errorf("expected empty result, got %+v", got)
+	}
+}
@@ -0,0 +1,238 @@
+package codeowner
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/kevin-robayna/codeowner/internal/scanning"
+)
+
+// Options configures Scan and Generate. Build it with Option functions; the
+// zero value of each field selects the default described on it.
+type Options struct {
+	// Prefix is the annotation prefix to search for. Defaults to
+	// DefaultPrefix.
+	Prefix string
+	// ExtraPrefixes are searched for in addition to Prefix.
+	ExtraPrefixes []string
+	// DirOwnerFile is the filename for directory-level ownership. Defaults
+	// to DefaultDirOwnerFile.
+	DirOwnerFile string
+	// HeaderLines restricts annotation scanning to each file's leading
+	// comment block of at most this many lines. Zero scans whole files.
+	HeaderLines int
+	// FollowSymlinks follows symbolic links that stay inside the root.
+	FollowSymlinks bool
+	// Filter reports whether a file or directory, given by its
+	// slash-separated path relative to the root, is scanned. Nil scans
+	// everything.
+	Filter func(path string, isDir bool) bool
+	// Concurrency is the number of files parsed in parallel. Defaults to
+	// runtime.GOMAXPROCS(0).
+	Concurrency int
+	// Format is the output format of Generate. Defaults to FormatCodeOwners.
+	Format Format
+	// Protect lists owners of the CODEOWNERS file itself. Empty adds no
+	// protect rule.
+	Protect []string
+	// Aliases maps owner handles used in annotations to the handles they
+	// stand for, expanded recursively in every mapping. Nil expands nothing.
+	Aliases map[string][]string
+	// Patterns are regular expressions matching annotations in addition to
+	// the prefixes.
+	Patterns []Pattern
+	// LanguageAnnotations also reads owners from language idioms: "Owner:"
+	// lines in Go package doc comments and Python module docstrings, and
+	// Python __owner__ variables.
+	LanguageAnnotations bool
+	// Metadata lists the package manifests, such as "package.json", whose
+	// declared owners become rules for the directories holding them. See
+	// MetadataFiles for the supported names.
+	Metadata []string
+	// BackstageOwner turns the group owning a Backstage catalog entity into
+	// a handle when Metadata includes "catalog-info.yaml", with {owner}
+	// standing for the group name, e.g. "@org/{owner}". Empty prefixes the
+	// name with @.
+	BackstageOwner string
+}
+
+// MetadataFiles lists the package manifest names Options.Metadata supports.
+func MetadataFiles() []string {
+	return scanning.MetadataFiles()
+}
+
+// Pattern matches annotations with a regular expression, as an alternative
+// to a fixed prefix, for ownership embedded in structured headers such as
+// "@owner {team: backend}".
+type Pattern struct {
+	// Regex is the regular expression matching an annotation. Its
+	// (?P<owners>...) group captures the owners, separated by whitespace or
+	// commas.
+	Regex string
+	// Owner turns each captured owner into a handle, with {owner} standing
+	// for the capture, e.g. "@org/{owner}". Empty uses captures starting
+	// with @ as they are and prefixes the others with @.
+	Owner string
+}
+
+// Option configures Options.
+type Option func(*Options)
+
+// WithPrefix sets the annotation prefix to search for.
+func WithPrefix(prefix string) Option {
+	return func(o *Options) { o.Prefix = prefix }
+}
+
+// WithExtraPrefixes sets annotation prefixes searched for in addition to the
+// main one, such as legacy conventions.
+func WithExtraPrefixes(prefixes ...string) Option {
+	return func(o *Options) { o.ExtraPrefixes = prefixes }
+}
+
+// WithDirOwnerFile sets the filename for directory-level ownership.
+func WithDirOwnerFile(name string) Option {
+	return func(o *Options) { o.DirOwnerFile = name }
+}
+
+// WithHeaderLines restricts scanning to each file's header of at most n lines.
+func WithHeaderLines(n int) Option {
+	return func(o *Options) { o.HeaderLines = n }
+}
+
+// WithFollowSymlinks sets whether symbolic links inside the root are followed.
+func WithFollowSymlinks(follow bool) Option {
+	return func(o *Options) { o.FollowSymlinks = follow }
+}
+
+// WithFilter sets the function deciding which files and directories are
+// scanned. Returning false for a directory skips everything below it.
+func WithFilter(filter func(path string, isDir bool) bool) Option {
+	return func(o *Options) { o.Filter = filter }
+}
+
+// WithConcurrency sets the number of files parsed in parallel.
+func WithConcurrency(n int) Option {
+	return func(o *Options) { o.Concurrency = n }
+}
+
+// WithFormat sets the output format of Generate.
+func WithFormat(format Format) Option {
+	return func(o *Options) { o.Format = format }
+}
+
+// WithProtect adds a rule making owners the owners of the CODEOWNERS file.
+func WithProtect(owners ...string) Option {
+	return func(o *Options) { o.Protect = owners }
+}
+
+// WithAliases sets the owner aliases expanded in every mapping, e.g.
+// "@payments" to "@org/payments-backend" and "@org/payments-sre".
+func WithAliases(aliases map[string][]string) Option {
+	return func(o *Options) { o.Aliases = aliases }
+}
+
+// WithPatterns sets the regular expressions matching annotations in
+// addition to the prefixes.
+func WithPatterns(patterns ...Pattern) Option {
+	return func(o *Options) { o.Patterns = patterns }
+}
+
+// WithLanguageAnnotations sets whether owners are also read from language
+// idioms such as Go package doc comments.
+func WithLanguageAnnotations(enabled bool) Option {
+	return func(o *Options) { o.LanguageAnnotations = enabled }
+}
+
+// WithMetadata reads the owners declared in the named package manifests,
+// such as "package.json", as owners of the directories holding them.
+func WithMetadata(fileNames ...string) Option {
+	return func(o *Options) { o.Metadata = fileNames }
+}
+
+// WithBackstageOwner sets the template turning Backstage groups into
+// handles, e.g. "@org/{owner}".
+func WithBackstageOwner(template string) Option {
+	return func(o *Options) { o.BackstageOwner = template }
+}
+
+// newOptions applies opts over the defaults and validates the result.
+func newOptions(opts []Option) (Options, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.Prefix == "" {
+		o.Prefix = DefaultPrefix
+	}
+	if o.DirOwnerFile == "" {
+		o.DirOwnerFile = DefaultDirOwnerFile
+	}
+	if o.Concurrency == 0 {
+		o.Concurrency = runtime.GOMAXPROCS(0)
+	}
+	if o.Format == "" {
+		o.Format = FormatCodeOwners
+	}
+
+	if o.HeaderLines < 0 {
+		return o, fmt.Errorf("header lines must not be negative, got %d", o.HeaderLines)
+	}
+	if o.Concurrency < 0 {
+		return o, fmt.Errorf("concurrency must not be negative, got %d", o.Concurrency)
+	}
+	if o.Format != FormatCodeOwners && o.Format != FormatJSON {
+		return o, fmt.Errorf("unknown format %q", o.Format)
+	}
+	if err := scanning.Aliases(o.Aliases).Validate(); err != nil {
+		return o, fmt.Errorf("aliases: %w", err)
+	}
+	for i, p := range o.patterns() {
+		if err := p.Compile(); err != nil {
+			return o, fmt.Errorf("pattern %d: %w", i+1, err)
+		}
+	}
+	if _, err := scanning.BuiltinMetadataReaders(o.Metadata...); err != nil {
+		return o, err
+	}
+	if err := (scanning.Backstage{Owner: o.BackstageOwner}).Validate(); err != nil {
+		return o, fmt.Errorf("backstage: %w", err)
+	}
+	return o, nil
+}
+
+// internal converts o to the options of the internal scanner.
+func (o Options) internal() scanning.Options {
+	// The manifest names were validated by newOptions.
+	readers, _ := scanning.BuiltinMetadataReaders(o.Metadata...)
+	for i, r := range readers {
+		if _, ok := r.(scanning.Backstage); ok {
+			readers[i] = scanning.Backstage{Owner: o.BackstageOwner}
+		}
+	}
+	return scanning.Options{
+		Prefix:              o.Prefix,
+		ExtraPrefixes:       o.ExtraPrefixes,
+		DirOwnerFile:        o.DirOwnerFile,
+		HeaderLines:         o.HeaderLines,
+		FollowSymlinks:      o.FollowSymlinks,
+		Filter:              o.Filter,
+		Concurrency:         o.Concurrency,
+		Aliases:             o.Aliases,
+		Patterns:            o.patterns(),
+		LanguageAnnotations: o.LanguageAnnotations,
+		MetadataReaders:     readers,
+	}
+}
+
+// patterns converts the patterns of o to those of the internal scanner.
+func (o Options) patterns() []scanning.Pattern {
+	if o.Patterns == nil {
+		return nil
+	}
+	out := make([]scanning.Pattern, len(o.Patterns))
+	for i, p := range o.Patterns {
+		out[i] = scanning.Pattern{Regex: p.Regex, Owner: p.Owner}
+	}
+	return out
+}